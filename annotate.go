@@ -0,0 +1,81 @@
+package oui
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// AnnotateCapture will read a CSV file with a Mac address in the first column,
+// and write the records to w with the manufacturer and the class of the
+// address added as two extra columns.
+// Other columns are written unchanged, so the output of most capture tools
+// exported as CSV can be annotated directly.
+// If the first record doesn't start with a Mac address, it is considered
+// a header, and "vendor" and "class" are added to it.
+// Locally administered addresses without an entry and addresses that are not found
+// get VendorLocallyAdministered and VendorNotFound as the manufacturer,
+// and records where the address cannot be parsed get an empty manufacturer
+// and "invalid" as the class.
+// Errors other than addresses not being found are returned.
+func AnnotateCapture(r io.Reader, db ReadOnlyDB, w io.Writer) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cw := csv.NewWriter(w)
+	for first := true; ; first = false {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(rec) == 0 {
+			continue
+		}
+		hw, perr := ParseMac(strings.TrimSpace(rec[0]))
+		switch {
+		case perr != nil && first:
+			rec = append(rec, "vendor", "class")
+		case perr != nil:
+			rec = append(rec, "", "invalid")
+		default:
+			vendor, err := annotateVendor(db, *hw)
+			if err != nil {
+				return err
+			}
+			rec = append(rec, vendor, annotateClass(db, *hw).String())
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Return the class of an address, using the database if it can classify addresses.
+func annotateClass(db ReadOnlyDB, hw HardwareAddr) AddressClass {
+	if c, ok := db.(interface {
+		Classify(HardwareAddr) AddressClass
+	}); ok {
+		return c.Classify(hw)
+	}
+	return classify(hw)
+}
+
+// Return the manufacturer to write for an address.
+// Locally administered addresses without an entry are written as VendorLocallyAdministered.
+func annotateVendor(db ReadOnlyDB, hw HardwareAddr) (string, error) {
+	e, err := db.LookUp(hw)
+	switch {
+	case errors.Is(err, ErrNotFound) && hw.Local() && !hw.Multicast():
+		return VendorLocallyAdministered, nil
+	case errors.Is(err, ErrNotFound):
+		return VendorNotFound, nil
+	case err != nil:
+		return "", err
+	}
+	return e.Manufacturer, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -22,7 +23,7 @@ var mu sync.RWMutex
 var loadWait *sync.Cond
 var updating bool
 
-const dbUrl = "http://standards-oui.ieee.org/oui.txt"
+var dbUrl = oui.RegistryURLs[oui.RegistryMAL]
 
 var gcsBucket string = gae_project() + ".appspot.com"
 var gcsPath string = gae_service() + "/oui.txt"
@@ -101,7 +102,7 @@ func update(c context.Context) {
 	}
 	defer r.Close()
 
-	err = oui.Update(db, r)
+	res, err := oui.UpdateWithResult(db, r)
 	if err != nil {
 		log.Printf("Error parsing: %s", err.Error())
 		return
@@ -110,7 +111,7 @@ func update(c context.Context) {
 	t := time.Now().Add(time.Hour * 24)
 	UpdateAt = &t
 
-	log.Printf("Updated database...")
+	log.Printf("Updated database, refresh: %s", res)
 }
 
 func updateHandler(w http.ResponseWriter, r *http.Request) {
@@ -219,7 +220,7 @@ func handler(w http.ResponseWriter, r *http.Request) {
 
 	entry, err := db.LookUp(*hw)
 	if err != nil {
-		if err == oui.ErrNotFound {
+		if errors.Is(err, oui.ErrNotFound) {
 			res.Error = "not found in db"
 			w.WriteHeader(http.StatusNotFound)
 			return
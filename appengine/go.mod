@@ -1,9 +1,13 @@
 module github.com/a1comms/oui/appengine
 
-go 1.15
+go 1.26.0
 
 require (
 	cloud.google.com/go/storage v1.12.0
 	github.com/klauspost/oui v0.0.0-20150225163751-35b4deb627f8
 	github.com/pquerna/ffjson v0.0.0-20190930134022-aa0246cd15f7 // indirect
+	golang.org/x/text v0.42.0 // indirect
 )
+
+// The server uses the package in this repository, not the pinned release.
+replace github.com/klauspost/oui => ../
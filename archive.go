@@ -0,0 +1,92 @@
+package oui
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"time"
+)
+
+// OpenArchive will open a zip archive, and read all registry files in it
+// into a single database.
+// Files in the oui.txt or Wireshark "manuf" format are read, other files are ignored.
+// This allows archives containing several registries, like oui.txt, oui36.txt and mam.txt,
+// to be read at once. If entries with the same prefix are found, the one read last is kept,
+// unless WithKeepDuplicates is given.
+// The generated time is the latest found in the files.
+// The Source of the entries is set to the name of the file they were read from,
+// unless WithSource is given.
+// WithMaxEntries limits the number of entries of all files together,
+// and the report given with WithParseReport covers all files,
+// with warnings prefixed by the file they were found in.
+// If the archive contains no registry files, ErrUnsupportedFormat is returned.
+// You can update the returned database using the Update/UpdateFile/UpdateHttp functions.
+// The options given are also used when the database is updated.
+func OpenArchive(r io.ReaderAt, size int64, opts ...Option) (DynamicDB, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	o := newOptions(opts)
+	dst := o.newDB()
+	var dups duplicates
+	var generated *time.Time
+	var report ParseReport
+	found := false
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		format, in, err := DetectFormat(rc)
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+		if format != FormatOUI && format != FormatManuf {
+			rc.Close()
+			continue
+		}
+		found = true
+		if o.maxEntries > 0 && len(dst) >= o.maxEntries {
+			rc.Close()
+			break
+		}
+		fo := o
+		if fo.source == "" {
+			fo.source = f.Name
+		}
+		if o.maxEntries > 0 {
+			fo.maxEntries = o.maxEntries - len(dst)
+		}
+		var fr ParseReport
+		fo.report = &fr
+		t, d, err := load(in, dst, fo)
+		rc.Close()
+		report.add(fr, f.Name)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		if t != nil && (generated == nil || t.After(*generated)) {
+			generated = t
+		}
+		for hw, e := range d {
+			if dups == nil {
+				dups = make(duplicates)
+			}
+			dups[hw] = append(dups[hw], e...)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no registry files in archive: %w", ErrUnsupportedFormat)
+	}
+	if o.report != nil {
+		*o.report = report
+	}
+	db := newDynamic(dst, dups, o)
+	db.generatedAt(generated)
+	return db, nil
+}
@@ -0,0 +1,77 @@
+package oui
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+// A zip archive with the given files from testdata.
+func testArchive(t *testing.T, names ...string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		b, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}
+	w, err := zw.Create("README")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("Not a registry.\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestOpenArchive(t *testing.T) {
+	names := []string{"oui.txt", "mam.txt", "oui36.txt"}
+	want := openRegistries(t, names...)
+	r := testArchive(t, names...)
+	var report ParseReport
+	db, err := OpenArchive(r, r.Size(), WithParseReport(&report))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != want.Len() {
+		t.Errorf("Len = %d, want %d", db.Len(), want.Len())
+	}
+	if report.Records != want.Len() {
+		t.Errorf("report has %d records, want %d for all files", report.Records, want.Len())
+	}
+	e, err := db.LookUpUint64(0x70b3d5f57abc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Source != "oui36.txt" {
+		t.Errorf("Source = %q, want the file name", e.Source)
+	}
+
+	for _, max := range []int{1, 7, want.Len() - 1} {
+		report = ParseReport{}
+		db, err := OpenArchive(r, r.Size(), WithMaxEntries(max), WithParseReport(&report))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The record stopping the file is read too.
+		if db.Len() != max || report.Records != max+1 {
+			t.Errorf("WithMaxEntries(%d): Len = %d with %d records", max, db.Len(), report.Records)
+		}
+	}
+
+	r = testArchive(t)
+	if _, err := OpenArchive(r, r.Size()); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("archive without registries: %v, want ErrUnsupportedFormat", err)
+	}
+}
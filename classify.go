@@ -0,0 +1,109 @@
+package oui
+
+import "sync"
+
+// AddressClass is the kind of a hardware address.
+type AddressClass int
+
+const (
+	// Universal is a universally administered unicast address,
+	// that can be looked up in the database.
+	Universal AddressClass = iota
+	// LocallyAdministered is a locally administered unicast address
+	// that doesn't match a known randomization pattern.
+	LocallyAdministered
+	// Multicast is a multicast address, other than broadcast.
+	Multicast
+	// Broadcast is the broadcast address ff:ff:ff:ff:ff:ff.
+	Broadcast
+	// Random is a locally administered unicast address matching
+	// a known pattern of randomized or privacy addresses.
+	Random
+)
+
+// String returns the name of the class.
+func (c AddressClass) String() string {
+	switch c {
+	case Universal:
+		return "universal"
+	case LocallyAdministered:
+		return "locally administered"
+	case Multicast:
+		return "multicast"
+	case Broadcast:
+		return "broadcast"
+	case Random:
+		return "random"
+	}
+	return "unknown"
+}
+
+// A pattern of bits that must match for an address to be random.
+type addrPattern struct {
+	value, mask HardwareAddr
+}
+
+var (
+	randomMu sync.RWMutex
+	// Randomized addresses created by operating systems for privacy,
+	// like Wi-Fi MAC randomization, are locally administered by definition.
+	// IEEE 802c recommends them to be in the administratively assigned
+	// quadrant, where the second hex digit is 2.
+	randomPatterns = []addrPattern{
+		{value: HardwareAddr{0x02, 0, 0}, mask: HardwareAddr{0x0f, 0, 0}},
+	}
+)
+
+// AddRandomPattern will add a pattern of randomized addresses to the patterns
+// used by the Classify method of the databases. A locally administered address is Random if the bits
+// set in mask are the same as in value, so a value of 12:34:00 with a mask
+// of ff:ff:00 matches all addresses starting with 12:34.
+// It is safe to call while other goroutines call Classify.
+func AddRandomPattern(value, mask HardwareAddr) {
+	for i := range value {
+		value[i] &= mask[i]
+	}
+	randomMu.Lock()
+	randomPatterns = append(randomPatterns, addrPattern{value: value, mask: mask})
+	randomMu.Unlock()
+}
+
+// Classify will return the class of a hardware address.
+// Only the first 24 bits of an address are known, so ff:ff:ff is
+// considered to be broadcast.
+// The database is consulted first, so locally administered addresses
+// with an entry in the database, like prefixes an organization has registered
+// or entries added with UpdateEntry, are LocallyAdministered,
+// even if they match a pattern of random addresses.
+// Addresses of other classes than Universal and LocallyAdministered
+// will never match a vendor.
+func (db *database) Classify(hw HardwareAddr) AddressClass {
+	c := classify(hw)
+	if c != Random {
+		return c
+	}
+	if _, err := db.LookUp(hw); err == nil {
+		return LocallyAdministered
+	}
+	return Random
+}
+
+// Return the class of an address from its bits and the random patterns.
+func classify(hw HardwareAddr) AddressClass {
+	switch {
+	case hw == HardwareAddr{0xff, 0xff, 0xff}:
+		return Broadcast
+	case hw.Multicast():
+		return Multicast
+	case !hw.Local():
+		return Universal
+	}
+	randomMu.RLock()
+	defer randomMu.RUnlock()
+	for _, p := range randomPatterns {
+		if hw[0]&p.mask[0] == p.value[0] && hw[1]&p.mask[1] == p.value[1] && hw[2]&p.mask[2] == p.value[2] {
+			return Random
+		}
+	}
+	return LocallyAdministered
+}
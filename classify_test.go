@@ -0,0 +1,69 @@
+package oui
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	db, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered := HardwareAddr{0x0a, 0x00, 0x01}
+	AddRandomPattern(HardwareAddr{0x0a, 0x00, 0x00}, HardwareAddr{0xff, 0xff, 0x00})
+	db.UpdateEntry(registered, Entry{Prefix: registered, Manufacturer: "Registered"})
+	tests := []struct {
+		hw   HardwareAddr
+		want AddressClass
+	}{
+		{hw: HardwareAddr{0x00, 0x60, 0x92}, want: Universal},
+		{hw: HardwareAddr{0x01, 0x00, 0x5e}, want: Multicast},
+		{hw: HardwareAddr{0xff, 0xff, 0xff}, want: Broadcast},
+		{hw: HardwareAddr{0x02, 0x00, 0x01}, want: Random},
+		{hw: HardwareAddr{0x06, 0x00, 0x01}, want: LocallyAdministered},
+		{hw: HardwareAddr{0x0a, 0x00, 0x02}, want: Random},
+		// Matches the added pattern, but is in the database.
+		{hw: registered, want: LocallyAdministered},
+	}
+	for _, test := range tests {
+		if got := db.Classify(test.hw); got != test.want {
+			t.Errorf("Classify(%s) = %s, want %s", test.hw, got, test.want)
+		}
+	}
+}
+
+func TestLookUpUniversal(t *testing.T) {
+	db, err := OpenStaticMutable("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.(io.Closer).Close()
+	// A locally administered prefix registered by an organization, in the overlay.
+	laa := HardwareAddr{0x0a, 0x00, 0x27}
+	db.UpdateEntry(laa, Entry{Manufacturer: "Local Org"})
+	tests := []struct {
+		hw   HardwareAddr
+		want string
+		err  error
+	}{
+		{hw: HardwareAddr{0x00, 0x22, 0x72}, want: "American Micro-Fuel Device Corp."},
+		{hw: laa, want: "Local Org"},
+		{hw: HardwareAddr{0x0a, 0x00, 0x28}, err: ErrLocallyAdministered},
+		{hw: HardwareAddr{0x01, 0x00, 0x5e}, err: ErrMulticast},
+	}
+	for _, test := range tests {
+		e, err := LookUpUniversal(db, test.hw)
+		switch {
+		case test.err != nil:
+			if !errors.Is(err, test.err) || !errors.Is(err, ErrNotUniversal) {
+				t.Errorf("LookUpUniversal(%s): %v, want %v", test.hw, err, test.err)
+			}
+		case err != nil:
+			t.Errorf("LookUpUniversal(%s): %v", test.hw, err)
+		case e.Manufacturer != test.want:
+			t.Errorf("LookUpUniversal(%s) = %q, want %q", test.hw, e.Manufacturer, test.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package oui
+
+import "time"
+
+// Clock provides the current time.
+// It allows logic depending on the age of a database to be tested
+// without waiting for time to pass.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is a Clock returning the current system time.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Age will return the time since the database was generated, according to clock.
+// If clock is nil, SystemClock is used.
+// If the generation time of the database is unknown, 0 is returned.
+func Age(db ReadOnlyDB, clock Clock) time.Duration {
+	t := db.Generated()
+	if t.IsZero() {
+		return 0
+	}
+	if clock == nil {
+		clock = SystemClock
+	}
+	return clock.Now().Sub(t)
+}
+
+// IsStale will return true if the database was generated more than maxAge ago,
+// according to clock.
+// If clock is nil, SystemClock is used.
+// A database with an unknown generation time is always considered stale.
+func IsStale(db ReadOnlyDB, maxAge time.Duration, clock Clock) bool {
+	if db.Generated().IsZero() {
+		return true
+	}
+	return Age(db, clock) > maxAge
+}
@@ -0,0 +1,95 @@
+package oui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A clock returning a time that can be changed by the test.
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.t
+}
+
+func TestWithClock(t *testing.T) {
+	clock := &testClock{t: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	db, err := OpenFile("testdata/oui.txt", WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := db.LoadedAt(); !got.Equal(clock.t) {
+		t.Errorf("LoadedAt = %v, want %v", got, clock.t)
+	}
+	clock.t = clock.t.Add(time.Hour)
+	if err := UpdateFile(db, "testdata/oui.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.LoadedAt(); !got.Equal(clock.t) {
+		t.Errorf("LoadedAt after update = %v, want %v", got, clock.t)
+	}
+
+	f, err := os.Open("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdb, err := OpenStaticReaderAt(f, st.Size(), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sdb.Close()
+	if got := sdb.LoadedAt(); !got.Equal(clock.t) {
+		t.Errorf("LoadedAt of reader database = %v, want %v", got, clock.t)
+	}
+}
+
+func TestHandlerClock(t *testing.T) {
+	db, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The test file has no generation time, so it is set by a delta.
+	if err := db.ApplyDelta(strings.NewReader("= 2020-01-02T03:04:05Z\n")); err != nil {
+		t.Fatal(err)
+	}
+	clock := &testClock{t: db.Generated().Add(time.Hour)}
+	h := NewHTTPHandler(db, WithMaxAge(2*time.Hour), WithRateLimit(1, 1), WithHandlerClock(clock))
+	health := func() int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		return w.Code
+	}
+	if code := health(); code != http.StatusOK {
+		t.Errorf("healthz = %d, want %d", code, http.StatusOK)
+	}
+	clock.t = clock.t.Add(2 * time.Hour)
+	if code := health(); code != http.StatusServiceUnavailable {
+		t.Errorf("healthz of stale database = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+
+	lookUp := func() int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/00-60-92", nil))
+		return w.Code
+	}
+	if code := lookUp(); code != http.StatusOK {
+		t.Fatalf("first lookup = %d, want %d", code, http.StatusOK)
+	}
+	if code := lookUp(); code != http.StatusTooManyRequests {
+		t.Errorf("second lookup = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	clock.t = clock.t.Add(time.Second)
+	if code := lookUp(); code != http.StatusOK {
+		t.Errorf("lookup after a second = %d, want %d", code, http.StatusOK)
+	}
+}
@@ -0,0 +1,57 @@
+package oui
+
+import "sort"
+
+// PrefixRange is a run of adjacent 24 bit prefixes assigned to the same manufacturer.
+// First and Last are the first and last prefix of the run, both included,
+// so a range with a single prefix has First equal to Last.
+// Count is the number of prefixes in the range.
+type PrefixRange struct {
+	First        HardwareAddr
+	Last         HardwareAddr
+	Manufacturer string
+	Count        int
+}
+
+// Returns the range as "First - Last Manufacturer", for instance "00-50-C2 - 00-50-C3 IEEE Registration Authority".
+// Ranges with a single prefix only have the prefix.
+func (p PrefixRange) String() string {
+	if p.First == p.Last {
+		return p.First.OUIString() + " " + p.Manufacturer
+	}
+	return p.First.OUIString() + " - " + p.Last.OUIString() + " " + p.Manufacturer
+}
+
+// Coalesce returns the entries grouped into ranges of adjacent
+// prefixes with the same manufacturer, sorted by prefix.
+// Manufacturers are compared by NormalizedManufacturer, and the range
+// has the manufacturer of the first entry. Lookups are not affected.
+// Only MA-L entries are grouped, MA-M and MA-S assignments are not included.
+func Coalesce(db OuiDB) []PrefixRange {
+	var entries []Entry
+	db.walk(func(e Entry) bool {
+		if e.Assignment().Bits == 24 {
+			entries = append(entries, e)
+		}
+		return true
+	})
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Assignment().less(entries[j].Assignment())
+	})
+	var res []PrefixRange
+	var name string
+	for _, e := range entries {
+		n := e.NormalizedManufacturer()
+		if len(res) > 0 {
+			last := &res[len(res)-1]
+			if n == name && prefixValue(e.Prefix) == prefixValue(last.Last)+1 {
+				last.Last = e.Prefix
+				last.Count++
+				continue
+			}
+		}
+		name = n
+		res = append(res, PrefixRange{First: e.Prefix, Last: e.Prefix, Manufacturer: e.Manufacturer, Count: 1})
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+package oui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// A record in the oui.txt format. If assignment is a range,
+// the record is an MA-M or MA-S record of the OUI.
+func record(oui, assignment, name string) string {
+	if assignment == "" {
+		assignment = strings.ReplaceAll(oui, "-", "")
+	}
+	return fmt.Sprintf("%s   (hex)\t\t%s\r\n%s     (base 16)\t\t%s\r\n\t\t\t\tUS\r\n\r\n", oui, name, assignment, name)
+}
+
+func TestCoalesce(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		records []string
+		want    []string
+	}{
+		{
+			name: "adjacent",
+			records: []string{
+				record("00-00-02", "", "Acme"),
+				record("00-00-01", "", "ACME"),
+				record("00-00-03", "", "Acme"),
+			},
+			want: []string{"00-00-01 - 00-00-03 ACME"},
+		},
+		{
+			name: "gap",
+			records: []string{
+				record("00-00-01", "", "Acme"),
+				record("00-00-03", "", "Acme"),
+				record("00-00-04", "", "Acme"),
+			},
+			want: []string{"00-00-01 Acme", "00-00-03 - 00-00-04 Acme"},
+		},
+		{
+			name: "other manufacturer",
+			records: []string{
+				record("00-00-01", "", "Acme"),
+				record("00-00-02", "", "Other"),
+				record("00-00-03", "", "Acme"),
+			},
+			want: []string{"00-00-01 Acme", "00-00-02 Other", "00-00-03 Acme"},
+		},
+		{
+			name: "mixed MA-S",
+			records: []string{
+				record("70-B3-D4", "", "Acme"),
+				record("70-B3-D5", "000000-000FFF", "Acme"),
+				record("70-B3-D5", "001000-001FFF", "Other"),
+				record("70-B3-D5", "", "IEEE Registration Authority"),
+				record("70-B3-D6", "", "IEEE Registration Authority"),
+			},
+			want: []string{"70-B3-D4 Acme", "70-B3-D5 - 70-B3-D6 IEEE Registration Authority"},
+		},
+		{
+			name: "MA-S only",
+			records: []string{
+				record("70-B3-D5", "000000-000FFF", "V1"),
+				record("70-B3-D5", "001000-001FFF", "V2"),
+				record("70-B3-D5", "002000-002FFF", "V3"),
+			},
+		},
+	} {
+		db, err := Open(strings.NewReader(strings.Join(test.records, "")))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		// The output must not depend on the order entries are walked in.
+		for i := 0; i < 10; i++ {
+			var got []string
+			for _, r := range Coalesce(db) {
+				got = append(got, r.String())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("%s: Coalesce = %q, want %q", test.name, got, test.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package oui
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// 24 bit prefixes the IEEE has subdivided into IAB and MA-S assignments.
+var subdivided = map[HardwareAddr]struct{}{
+	{0x00, 0x1b, 0xc5}: {}, // MA-S
+	{0x00, 0x50, 0xc2}: {}, // IAB
+	{0x40, 0xd8, 0x55}: {}, // IAB
+	{0x70, 0xb3, 0xd5}: {}, // MA-S
+	{0x8c, 0x1f, 0x64}: {}, // MA-S
+}
+
+// Prefixes subdivided by the IEEE are assigned to the registration authority itself.
+const registrationAuthority = "IEEE Registration Authority"
+
+// Returns true if the entry is for a 24 bit prefix the IEEE is known to subdivide.
+func (e Entry) coarse() bool {
+	if e.bits() != 24 {
+		return false
+	}
+	_, ok := subdivided[e.Prefix]
+	return ok || strings.EqualFold(strings.TrimSpace(e.Manufacturer), registrationAuthority)
+}
+
+// CoarsePrefixes returns the 24 bit prefixes in the database that
+// the IEEE is known to subdivide into smaller assignments, sorted by prefix.
+// These are either in a list of known prefixes, or assigned to
+// the IEEE Registration Authority, which is how MA-M blocks are listed.
+// MA-M and MA-S entries are not returned themselves.
+func CoarsePrefixes(db OuiDB) []HardwareAddr {
+	var res []HardwareAddr
+	db.walk(func(e Entry) bool {
+		if e.coarse() {
+			res = append(res, e.Prefix)
+		}
+		return true
+	})
+	sort.Slice(res, func(i, j int) bool {
+		return bytes.Compare(res[i][:], res[j][:]) < 0
+	})
+	return res
+}
+
+// CoverageByFirstOctet returns the number of entries for each value
+// of the first octet of the prefix, so index 0x00 has the number of entries
+// with a prefix starting with 00.
+// Entries shadowed by another entry with the same prefix are not counted.
+func CoverageByFirstOctet(db OuiDB) [256]int {
+	var res [256]int
+	db.walk(func(e Entry) bool {
+		res[e.Prefix[0]]++
+		return true
+	})
+	return res
+}
@@ -0,0 +1,56 @@
+package oui
+
+// Confidence is a rough score of how likely an entry returned by a lookup
+// is to be the actual assignee of an address.
+//
+// Lookups return the entry with the longest assignment containing the known
+// bits of an address, which are the first 24 bits for LookUp, so:
+//   - An entry is ConfidenceHigh if no other entries are assigned within
+//     the known bits.
+//   - An entry is ConfidenceLow if the known bits are subdivided into longer
+//     assignments, like an OUI with MA-S entries looked up with LookUp,
+//     since the address may belong to another assignee in the same OUI.
+//   - An entry for an OUI the IEEE is known to subdivide, see CoarsePrefixes,
+//     is ConfidenceLow, since the longer assignments may not be loaded.
+//   - Entries returned by LookUpCandidates are ConfidenceLow if there
+//     is more than one candidate, or the candidate is an MA-M or MA-S entry.
+//   - An entry returned by Nearest for a prefix that isn't the
+//     address is ConfidenceVeryLow.
+//   - An entry returned by LookUpBSSID for an address with the locally
+//     administered bit cleared is ConfidenceVeryLow.
+//
+// Entries that weren't returned by a lookup have ConfidenceUnknown.
+type Confidence int
+
+const (
+	// ConfidenceUnknown is used when no score has been given.
+	ConfidenceUnknown Confidence = iota
+	// ConfidenceVeryLow is used for heuristic matches.
+	ConfidenceVeryLow
+	// ConfidenceLow is used when the address could belong to other entries.
+	ConfidenceLow
+	// ConfidenceHigh is used when the entry is the only possible assignee.
+	ConfidenceHigh
+)
+
+// String returns the name of the confidence.
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceVeryLow:
+		return "very low"
+	case ConfidenceLow:
+		return "low"
+	case ConfidenceHigh:
+		return "high"
+	}
+	return "unknown"
+}
+
+// Set the confidence of an entry returned by a lookup.
+// subdivided is true if there are longer assignments within the known bits of the address.
+func setConfidence(e *Entry, subdivided bool) {
+	e.Confidence = ConfidenceHigh
+	if subdivided || e.coarse() {
+		e.Confidence = ConfidenceLow
+	}
+}
@@ -0,0 +1,95 @@
+package oui
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConfidence(t *testing.T) {
+	db := openRegistries(t, "oui.txt", "mam.txt", "oui36.txt")
+	tests := []struct {
+		mac  uint64
+		want string
+		conf Confidence
+	}{
+		// MA-S blocks in the same OUI: the full address picks the right one.
+		{mac: 0x70b3d50e0123, want: "Grossenbacher Systeme AG", conf: ConfidenceHigh},
+		{mac: 0x70b3d5f57abc, want: "Aeronautics Ltd.", conf: ConfidenceHigh},
+		// Outside the loaded blocks, so only the parent is known.
+		{mac: 0x70b3d5000001, want: registrationAuthority, conf: ConfidenceLow},
+		{mac: 0x0055daa12345, want: "Speechlab", conf: ConfidenceHigh},
+		{mac: 0x002272000001, want: "American Micro-Fuel Device Corp.", conf: ConfidenceHigh},
+	}
+	for _, test := range tests {
+		e, err := db.LookUpUint64(test.mac)
+		if err != nil {
+			t.Fatalf("LookUpUint64(%012x): %v", test.mac, err)
+		}
+		if e.Manufacturer != test.want || e.Confidence != test.conf {
+			t.Errorf("LookUpUint64(%012x) = %q with %s confidence, want %q with %s", test.mac, e.Manufacturer, e.Confidence, test.want, test.conf)
+		}
+	}
+
+	// Only the OUI is known, so the address may belong to any of the blocks.
+	e, err := db.LookUp(HardwareAddr{0x70, 0xb3, 0xd5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != registrationAuthority || e.Confidence != ConfidenceLow {
+		t.Errorf("LookUp = %q with %s confidence, want the parent with low confidence", e.Manufacturer, e.Confidence)
+	}
+	e, err = db.LookUp(HardwareAddr{0x00, 0x22, 0x72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Confidence != ConfidenceHigh {
+		t.Errorf("LookUp(00:22:72) confidence = %s, want high", e.Confidence)
+	}
+
+	want := []HardwareAddr{{0x00, 0x1b, 0xc5}, {0x00, 0x55, 0xda}, {0x70, 0xb3, 0xd5}}
+	got := CoarsePrefixes(db)
+	if len(got) != len(want) {
+		t.Fatalf("CoarsePrefixes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CoarsePrefixes = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestConfidenceWithoutParent(t *testing.T) {
+	db := openRegistries(t, "oui36.txt")
+	var nf NotFoundError
+	if _, err := db.LookUp(HardwareAddr{0x70, 0xb3, 0xd5}); !errors.As(err, &nf) {
+		t.Errorf("LookUp of an OUI with only MA-S entries: %v, want not found", err)
+	}
+	c, err := db.LookUpCandidates(HardwareAddr{0x70, 0xb3, 0xd5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 2 {
+		t.Fatalf("LookUpCandidates returned %d entries, want 2", len(c))
+	}
+	for _, e := range c {
+		if e.Confidence != ConfidenceLow {
+			t.Errorf("candidate %q has %s confidence, want low", e.Manufacturer, e.Confidence)
+		}
+	}
+	// A single MA-S candidate is low too, since the OUI may hold other blocks.
+	c, err = db.LookUpCandidates(HardwareAddr{0x00, 0x1b, 0xc5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 1 || c[0].Confidence != ConfidenceLow {
+		t.Errorf("LookUpCandidates(00:1b:c5) = %d entries, want 1 with low confidence", len(c))
+	}
+	e, err := db.LookUpUint64(0x001bc5000123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != "Converging Systems Inc." || e.Confidence != ConfidenceHigh {
+		t.Errorf("LookUpUint64 = %q with %s confidence", e.Manufacturer, e.Confidence)
+	}
+}
@@ -0,0 +1,187 @@
+package oui
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Tags are stored in a single CSV column, separated by this.
+const csvTagSeparator = ";"
+
+// Layouts tried when parsing dates in CSV files.
+var csvDateLayouts = []string{"2006-01-02", time.RFC3339, "2006/01/02", "01/02/2006"}
+
+// OpenCSV will read the CSV format published by the IEEE and return a database with the content.
+// The first line must be a header with at least the "Assignment" and
+// "Organization Name" columns. The "Registry" and "Organization Address"
+// columns are used if present. Quoted fields can contain newlines, and each
+// line of a multiline address is stored as a line of Entry.Address.
+// If the file has a column with a registration or update date, like
+// "Date Registered" or "Last Updated", it is stored as Entry.Registered.
+// Dates that cannot be parsed are left as the zero time.
+// A "Tags" column is read as Entry.Tags, with tags separated by ';'.
+// A "Registration ID" or "Registration Number" column is read as Entry.RegistrationID.
+// If a line cannot be decoded, an ErrInvalidRecord with the line number is returned,
+// unless WithLenientParsing is given.
+// You can update the returned database using the Update/UpdateFile/UpdateHttp functions,
+// which will read the new content as CSV too.
+func OpenCSV(r io.Reader, opts ...Option) (DynamicDB, error) {
+	o := newOptions(opts)
+	o.format = FormatCSV
+	dst := o.newDB()
+	t, dups, err := load(r, dst, o)
+	if err != nil {
+		return nil, err
+	}
+	db := newDynamic(dst, dups, o)
+	db.generatedAt(t)
+	return db, nil
+}
+
+// The columns of a CSV file. Columns not in the file are -1.
+type csvColumns struct {
+	registry, assignment, name, address, date, tags, id int
+}
+
+// Find the columns from the header of a CSV file.
+func newCSVColumns(header []string) csvColumns {
+	c := csvColumns{registry: -1, assignment: -1, name: -1, address: -1, date: -1, tags: -1, id: -1}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		switch {
+		case h == "registry":
+			c.registry = i
+		case h == "assignment":
+			c.assignment = i
+		case h == "organization name":
+			c.name = i
+		case h == "organization address":
+			c.address = i
+		case h == "tags":
+			c.tags = i
+		case h == "registration id" || h == "registration number":
+			c.id = i
+		case c.date < 0 && (strings.Contains(h, "date") || strings.Contains(h, "updated") || strings.Contains(h, "registered")):
+			c.date = i
+		}
+	}
+	return c
+}
+
+// Read the records of a CSV file.
+// Records that cannot be decoded are skipped if lenient parsing is enabled in o.
+func scanCSV(in io.Reader, o options, fn recordFunc) error {
+	report := o.report
+	if report == nil {
+		report = &ParseReport{}
+	}
+	*report = ParseReport{}
+	cr := csv.NewReader(in)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	cols := newCSVColumns(header)
+	if cols.assignment < 0 || cols.name < 0 {
+		return ErrInvalidRecord{Line: 1, Reason: "header must have Assignment and Organization Name columns"}
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// Only errors in the CSV syntax can be skipped.
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				return err
+			}
+			invalid := ErrInvalidRecord{Line: perr.Line, Reason: perr.Err.Error()}
+			if o.warn(report, invalid) {
+				continue
+			}
+			return invalid
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(i int) string {
+			if i < 0 || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		e, err := csvEntry(field(cols.assignment), field(cols.registry))
+		if err != nil {
+			invalid := ErrInvalidRecord{Line: line, Reason: err.Error()}
+			if o.warn(report, invalid) {
+				continue
+			}
+			return invalid
+		}
+		e.Manufacturer = strings.Join(strings.Fields(field(cols.name)), " ")
+		// Quoted addresses can span several lines, which are kept as separate lines.
+		for _, a := range strings.Split(field(cols.address), "\n") {
+			if a = strings.TrimSpace(a); a != "" {
+				e.Address = append(e.Address, a)
+			}
+		}
+		if len(e.Address) > 0 {
+			// The address ends with the country code.
+			f := strings.Fields(e.Address[len(e.Address)-1])
+			if len(f[len(f)-1]) == 2 {
+				e.Country = f[len(f)-1]
+			}
+		}
+		e.RegistrationID = field(cols.id)
+		setFlags(e)
+		for _, t := range strings.Split(field(cols.tags), csvTagSeparator) {
+			if t = strings.TrimSpace(t); t != "" {
+				e.Tags = append(e.Tags, t)
+			}
+		}
+		if d := field(cols.date); d != "" {
+			for _, layout := range csvDateLayouts {
+				if t, err := time.Parse(layout, d); err == nil {
+					e.Registered = t
+					break
+				}
+			}
+		}
+		report.Records++
+		if err := fn(*e, 0, 0); err != nil {
+			return err
+		}
+	}
+}
+
+// Create an entry from the hex assignment of a CSV record.
+func csvEntry(assignment, registry string) (*Entry, error) {
+	digits := assignment
+	if len(digits)%2 == 1 {
+		digits += "0"
+	}
+	b, err := hex.DecodeString(digits)
+	if err != nil || len(b) < 3 || len(b) > 6 {
+		return nil, ErrInvalidMac{Reason: "Assignment must be between 6 and 12 hex digits", Mac: assignment}
+	}
+	e := Entry{Prefix: HardwareAddr{b[0], b[1], b[2]}}
+	copy(e.Extension[:], b[3:])
+	if len(assignment) > 6 {
+		e.PrefixLen = len(assignment) * 4
+	}
+	// The registry decides the length if the assignment is padded.
+	switch Registry(strings.ToUpper(registry)) {
+	case RegistryMAM:
+		e.PrefixLen = 28
+	case RegistryMAS, RegistryIAB:
+		e.PrefixLen = 36
+	}
+	return &e, nil
+}
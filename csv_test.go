@@ -0,0 +1,35 @@
+package oui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOpenCSVMultilineAddress(t *testing.T) {
+	const records = "Registry,Assignment,Organization Name,Organization Address\r\n" +
+		"MA-L,002272,American Micro-Fuel Device Corp.,\"2181 Buchanan Loop\r\nFerndale  WA  98248\r\n\r\nUS\"\r\n" +
+		"MA-S,70B3D5F57,Aeronautics Ltd.,\"Nahal Snir 10, Yavne IL 81101\"\r\n"
+	db, err := OpenCSV(strings.NewReader(records))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", db.Len())
+	}
+	e, err := db.LookUp(HardwareAddr{0x00, 0x22, 0x72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2181 Buchanan Loop", "Ferndale  WA  98248", "US"}
+	if strings.Join(e.Address, "|") != strings.Join(want, "|") {
+		t.Errorf("Address = %q, want %q", e.Address, want)
+	}
+	// The record after the multiline field is read as its own record.
+	e, err = db.LookUpUint64(0x70b3d5f57abc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != "Aeronautics Ltd." || len(e.Address) != 1 {
+		t.Errorf("LookUpUint64 = %q with %d address lines", e.Manufacturer, len(e.Address))
+	}
+}
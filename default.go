@@ -0,0 +1,54 @@
+package oui
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotInitialized will be returned when a database is needed,
+// but none has been set up.
+var ErrNotInitialized = errors.New("database not initialized")
+
+// The database used by package level helpers.
+var defaultDB struct {
+	mu sync.RWMutex
+	db ReadOnlyDB
+}
+
+// SetDefault will set the database used by package level helpers,
+// like HardwareAddr.Vendor.
+// Setting it to nil will remove the default database.
+func SetDefault(db ReadOnlyDB) {
+	defaultDB.mu.Lock()
+	defaultDB.db = db
+	defaultDB.mu.Unlock()
+}
+
+// Default returns the database set with SetDefault.
+// If none has been set, nil is returned.
+func Default() ReadOnlyDB {
+	defaultDB.mu.RLock()
+	defer defaultDB.mu.RUnlock()
+	return defaultDB.db
+}
+
+// Vendor will look up the address in the default database
+// and return the manufacturer.
+// Addresses reserved for protocols return the label from WellKnown
+// without consulting the database.
+// If no default database has been set, ErrNotInitialized is returned.
+// Use LookUp on a database to get the entire entry.
+func (h HardwareAddr) Vendor() (string, error) {
+	if s, ok := WellKnown(h); ok {
+		return s, nil
+	}
+	db := Default()
+	if db == nil {
+		return "", ErrNotInitialized
+	}
+	e, err := db.LookUp(h)
+	if err != nil {
+		return "", err
+	}
+	return e.Manufacturer, nil
+}
@@ -0,0 +1,309 @@
+package oui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Delta operations. See Updater.ApplyDelta for the format.
+const (
+	deltaAdded     = '+'
+	deltaChanged   = '~'
+	deltaRemoved   = '-'
+	deltaGenerated = '='
+)
+
+// A parsed delta.
+type delta struct {
+	set       []Entry
+	del       []Prefix
+	generated *time.Time
+}
+
+// ErrInvalidDelta will be returned by ApplyDelta
+// if the delta cannot be decoded.
+type ErrInvalidDelta struct {
+	Line   int
+	Reason string
+}
+
+// Error returns a string representation of the error.
+func (e ErrInvalidDelta) Error() string {
+	return fmt.Sprintf("invalid delta on line %d: %s", e.Line, e.Reason)
+}
+
+// DiffResult contains the differences between two databases.
+// All slices are sorted by prefix.
+type DiffResult struct {
+	// Entries only present in the new database.
+	Added []*Entry
+	// Entries only present in the old database.
+	Removed []*Entry
+	// Entries present in both databases with different content.
+	// The entry from the new database is stored.
+	Changed []*Entry
+	// The generation time of the new database.
+	Generated time.Time
+}
+
+// UpdateResult is the number of entries changed by UpdateWithResult.
+type UpdateResult struct {
+	Added, Removed, Changed int
+}
+
+// String returns the result as "+added -removed ~changed".
+func (u UpdateResult) String() string {
+	return fmt.Sprintf("+%d -%d ~%d", u.Added, u.Removed, u.Changed)
+}
+
+// Count the differences between the entries of db and the new content.
+func updateResult(db walker, content ouiDB) (UpdateResult, error) {
+	var res UpdateResult
+	seen := 0
+	err := db.walk(func(e Entry) bool {
+		n, ok := content[e.Assignment()]
+		switch {
+		case !ok:
+			res.Removed++
+		case !n.Equal(&e):
+			res.Changed++
+			seen++
+		default:
+			seen++
+		}
+		return true
+	})
+	res.Added = len(content) - seen
+	return res, err
+}
+
+// Diff will compare two databases and return the differences.
+func Diff(old, new OuiDB) (*DiffResult, error) {
+	before := make(ouiDB)
+	err := old.walk(func(e Entry) bool {
+		before.set(e)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &DiffResult{Generated: new.Generated()}
+	err = new.walk(func(e Entry) bool {
+		prev, ok := before[e.Assignment()]
+		if !ok {
+			res.Added = append(res.Added, &e)
+			return true
+		}
+		if !prev.Equal(&e) {
+			res.Changed = append(res.Changed, &e)
+		}
+		before.del(e.Assignment())
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range before {
+		e := e
+		res.Removed = append(res.Removed, &e)
+	}
+	sortEntries(res.Added)
+	sortEntries(res.Removed)
+	sortEntries(res.Changed)
+	return res, nil
+}
+
+// Equal returns true if both databases have the same generation time,
+// and the same entries for all assignments. Entries are compared with Entry.Equal,
+// so the Source and Confidence of the entries are ignored.
+// Databases that cannot be enumerated, like RemoteFallbackDB, are never equal
+// to anything. If a database returns an error while reading the entries,
+// false is returned.
+func Equal(a, b ReadOnlyDB) bool {
+	wa, ok := a.(walker)
+	if !ok {
+		return false
+	}
+	wb, ok := b.(walker)
+	if !ok || !a.Generated().Equal(b.Generated()) {
+		return false
+	}
+	entries := make(ouiDB)
+	if err := wa.walk(func(e Entry) bool {
+		entries.set(e)
+		return true
+	}); err != nil {
+		return false
+	}
+	equal := true
+	n := 0
+	err := wb.walk(func(e Entry) bool {
+		prev, ok := entries[e.Assignment()]
+		equal = ok && prev.Equal(&e)
+		n++
+		return equal
+	})
+	return err == nil && equal && n == len(entries)
+}
+
+// WriteDelta will write the differences in the delta format
+// read by ApplyDelta.
+// Applying the delta to the old database will make it contain
+// the same entries as the new database.
+func (d *DiffResult) WriteDelta(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if !d.Generated.IsZero() {
+		fmt.Fprintf(bw, "%c %s\n", deltaGenerated, d.Generated.Format(time.RFC3339Nano))
+	}
+	for _, e := range d.Removed {
+		fmt.Fprintf(bw, "%c %s\n", deltaRemoved, deltaPrefix(e.Assignment()))
+	}
+	write := func(op byte, entries []*Entry) error {
+		for _, e := range entries {
+			j, err := e.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(bw, "%c %s\n", op, j)
+		}
+		return nil
+	}
+	if err := write(deltaChanged, d.Changed); err != nil {
+		return err
+	}
+	if err := write(deltaAdded, d.Added); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Format an assignment removed by a delta.
+// 24 bit assignments are written as the OUI, like deltas have always been.
+func deltaPrefix(p Prefix) string {
+	if p.Bits == 24 {
+		return p.OUI().String()
+	}
+	return p.String()
+}
+
+// Read an entire delta.
+func readDelta(r io.Reader) (*delta, error) {
+	d := &delta{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		if len(text) < 2 || text[1] != ' ' {
+			return nil, ErrInvalidDelta{Line: line, Reason: "expected operation followed by a space"}
+		}
+		value := text[2:]
+		switch text[0] {
+		case deltaAdded, deltaChanged:
+			var e Entry
+			if err := json.Unmarshal([]byte(value), &e); err != nil {
+				return nil, ErrInvalidDelta{Line: line, Reason: err.Error()}
+			}
+			d.set = append(d.set, e)
+		case deltaRemoved:
+			p, err := ParsePrefix(value)
+			if err != nil {
+				return nil, ErrInvalidDelta{Line: line, Reason: err.Error()}
+			}
+			d.del = append(d.del, *p)
+		case deltaGenerated:
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, ErrInvalidDelta{Line: line, Reason: err.Error()}
+			}
+			d.generated = &t
+		default:
+			return nil, ErrInvalidDelta{Line: line, Reason: fmt.Sprintf("unknown operation '%c'", text[0])}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ApplyDelta will read a delta and apply it to the database.
+// The entire delta is read before the database is modified, and the changes
+// are applied while queries are blocked, so queries will either see the database
+// before or after the delta has been applied.
+// If an error occurs during read or parsing, the database is left untouched.
+// If an Index returns an error while the delta is applied, the entries
+// changed so far are restored and the error is returned.
+func (o updateableDB) ApplyDelta(r io.Reader) error {
+	d, err := readDelta(r)
+	if err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.modify()
+	if err := d.apply(o.writable()); err != nil {
+		return err
+	}
+	o.generatedAt(d.generated)
+	return nil
+}
+
+// Apply the delta to the store.
+// The entries of the assignments in the delta are saved before they are changed,
+// so they can be restored if the store returns an error.
+func (d *delta) apply(st writableStore) error {
+	type saved struct {
+		k     Prefix
+		e     Entry
+		found bool
+	}
+	var undo []saved
+	save := func(k Prefix) error {
+		e, found, err := st.get(k)
+		if err != nil {
+			return err
+		}
+		// Only an entry for the exact assignment is changed.
+		found = found && e.Assignment() == k
+		undo = append(undo, saved{k: k, e: e, found: found})
+		return nil
+	}
+	restore := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if u := undo[i]; u.found {
+				st.set(u.e)
+			} else {
+				st.del(u.k)
+			}
+		}
+	}
+	for _, k := range d.del {
+		err := save(k)
+		if err == nil {
+			err = st.del(k)
+		}
+		if err != nil {
+			restore()
+			return err
+		}
+	}
+	for _, e := range d.set {
+		err := save(e.Assignment())
+		if err == nil {
+			err = st.set(e)
+		}
+		if err != nil {
+			restore()
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package oui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeltaRoundTrip(t *testing.T) {
+	old := openRegistries(t, "oui.txt", "mam.txt", "oui36.txt")
+	updated := openRegistries(t, "oui.txt", "mam.txt", "oui36.txt")
+	changes := "= 2026-10-14T07:41:02.123456789Z\n" +
+		"- 00:60:92\n" +
+		"- 70:b3:d5:0e:00:00/36\n" +
+		`~ {"prefix":"00:22:72","manufacturer":"Renamed Corp."}` + "\n" +
+		`+ {"prefix":"70:b3:d5","extension":"12:30:00","prefix_len":36,"manufacturer":"New Sensors"}` + "\n"
+	if err := updated.ApplyDelta(strings.NewReader(changes)); err != nil {
+		t.Fatal(err)
+	}
+	if got := updated.Generated().Nanosecond(); got != 123456789 {
+		t.Fatalf("generated nanoseconds = %d", got)
+	}
+	d, err := Diff(old, updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Added) != 1 || len(d.Removed) != 2 || len(d.Changed) != 1 {
+		t.Errorf("Diff = +%d -%d ~%d, want +1 -2 ~1", len(d.Added), len(d.Removed), len(d.Changed))
+	}
+	var buf bytes.Buffer
+	if err := d.WriteDelta(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := old.ApplyDelta(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !old.Generated().Equal(updated.Generated()) {
+		t.Errorf("generated = %s, want %s", old.Generated().Format(time.RFC3339Nano), updated.Generated().Format(time.RFC3339Nano))
+	}
+	if !Equal(old, updated) {
+		t.Error("database is not equal to the new database after applying the delta")
+	}
+}
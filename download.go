@@ -0,0 +1,227 @@
+package oui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Downloader will download registry files over HTTP.
+// If a transfer fails, the remaining bytes are requested with a HTTP range
+// request, so the download is resumed rather than restarted.
+// The zero value is usable, and will download with http.DefaultClient
+// without a bandwidth limit and 3 retries.
+type Downloader struct {
+	// Client is used for requests. If nil http.DefaultClient is used.
+	Client *http.Client
+
+	// MaxBytesPerSecond limits the bandwidth used. 0 means no limit.
+	MaxBytesPerSecond int64
+
+	// Retries is the number of times a failed transfer is resumed.
+	// If 0, 3 retries will be done. Set to a negative value to disable retries.
+	Retries int
+
+	// RetryDelay is the time to wait before resuming a transfer.
+	RetryDelay time.Duration
+}
+
+// Download will download the content at url.
+func (d *Downloader) Download(url string) ([]byte, error) {
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	retries := d.Retries
+	if retries == 0 {
+		retries = 3
+	}
+	var buf bytes.Buffer
+	// Validator sent with range requests, so changed content is sent in full.
+	var validator string
+	var err error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if attempt > retries {
+				return nil, err
+			}
+			time.Sleep(d.RetryDelay)
+		}
+		var req *http.Request
+		req, err = http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 0 && validator != "" {
+			req.Header.Set("Range", "bytes="+strconv.Itoa(buf.Len())+"-")
+			req.Header.Set("If-Range", validator)
+		}
+		var resp *http.Response
+		resp, err = client.Do(req)
+		if err != nil {
+			continue
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			// Full content, start over.
+			buf.Reset()
+			validator = resp.Header.Get("ETag")
+			if validator == "" {
+				validator = resp.Header.Get("Last-Modified")
+			}
+		case http.StatusPartialContent:
+			if !strings.HasPrefix(resp.Header.Get("Content-Range"), "bytes "+strconv.Itoa(buf.Len())+"-") {
+				// Not the range we asked for, start over.
+				resp.Body.Close()
+				buf.Reset()
+				validator = ""
+				err = fmt.Errorf("unexpected range downloading %s: %s", url, resp.Header.Get("Content-Range"))
+				continue
+			}
+		default:
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status downloading %s: %s", url, resp.Status)
+		}
+		_, err = io.Copy(&buf, d.throttle(resp.Body))
+		resp.Body.Close()
+		if err == nil {
+			return buf.Bytes(), nil
+		}
+	}
+}
+
+// Open will download the registry file at url and return a database with the content.
+func (d *Downloader) Open(url string, opts ...Option) (DynamicDB, error) {
+	b, err := d.Download(url)
+	if err != nil {
+		return nil, err
+	}
+	return Open(bytes.NewReader(b), opts...)
+}
+
+// The registries downloaded by OpenRegistries if none are given,
+// from the largest to the smallest assignments.
+var allRegistries = []Registry{RegistryMAL, RegistryMAM, RegistryMAS, RegistryIAB}
+
+// OpenRegistries will download the given registries from RegistryURLs,
+// and merge them into a single database.
+// If no registries are given, all are downloaded.
+// Entries are keyed by their assignment, so MA-M and MA-S entries are kept
+// next to the MA-L entry of their OUI, and lookups return the longest match.
+// Registries are merged in the order given, so if entries for the same assignment
+// are found, the one from the last registry is kept, unless WithKeepDuplicates is given.
+// The generated time is the latest found in the registries.
+// The Source of the entries is set to the registry they were read from,
+// unless WithSource is given.
+// WithMaxEntries limits the number of entries of all registries together,
+// and the report given with WithParseReport covers all registries,
+// with warnings prefixed by the registry they were found in.
+func (d *Downloader) OpenRegistries(registries []Registry, opts ...Option) (DynamicDB, error) {
+	if len(registries) == 0 {
+		registries = allRegistries
+	}
+	o := newOptions(opts)
+	dst := o.newDB()
+	var dups duplicates
+	var generated *time.Time
+	var report ParseReport
+	for _, r := range registries {
+		url, ok := RegistryURLs[r]
+		if !ok {
+			return nil, fmt.Errorf("unknown registry %q", r)
+		}
+		if o.maxEntries > 0 && len(dst) >= o.maxEntries {
+			break
+		}
+		b, err := d.Download(url)
+		if err != nil {
+			return nil, err
+		}
+		ro := o
+		if ro.source == "" {
+			ro.source = string(r)
+		}
+		if o.maxEntries > 0 {
+			ro.maxEntries = o.maxEntries - len(dst)
+		}
+		var fr ParseReport
+		ro.report = &fr
+		t, dd, err := load(bytes.NewReader(b), dst, ro)
+		report.add(fr, string(r))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", r, err)
+		}
+		if t != nil && (generated == nil || t.After(*generated)) {
+			generated = t
+		}
+		for k, e := range dd {
+			if dups == nil {
+				dups = make(duplicates)
+			}
+			dups[k] = append(dups[k], e...)
+		}
+	}
+	if o.report != nil {
+		*o.report = report
+	}
+	db := newDynamic(dst, dups, o)
+	db.generatedAt(generated)
+	return db, nil
+}
+
+// Add the report of a registry read by OpenRegistries or a file read by OpenArchive.
+// Warnings are prefixed by the name of the registry or file.
+func (p *ParseReport) add(r ParseReport, name string) {
+	p.Records += r.Records
+	p.IgnoredLines += r.IgnoredLines
+	p.FooterBytes += r.FooterBytes
+	p.TruncatedAddresses += r.TruncatedAddresses
+	for _, w := range r.Warnings {
+		p.Warnings = append(p.Warnings, fmt.Errorf("%s: %w", name, w))
+	}
+}
+
+// Update will download the registry file at url and replace the content of the database.
+// If an error occurs during download or parsing, the database will not be replaced
+// and the previous version will continue to be served.
+func (d *Downloader) Update(db DynamicDB, url string) error {
+	b, err := d.Download(url)
+	if err != nil {
+		return err
+	}
+	return Update(db, bytes.NewReader(b))
+}
+
+// Apply the bandwidth limit to a reader.
+func (d *Downloader) throttle(r io.Reader) io.Reader {
+	if d.MaxBytesPerSecond <= 0 {
+		return r
+	}
+	return &throttledReader{r: r, rate: d.MaxBytesPerSecond, start: time.Now()}
+}
+
+// A reader that sleeps to keep the average rate below a limit.
+type throttledReader struct {
+	r     io.Reader
+	rate  int64
+	start time.Time
+	read  int64
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	// Read at most 1/10th of a second worth of data at the time.
+	if max := t.rate / 10; max > 0 && int64(len(p)) > max {
+		p = p[:max]
+	}
+	n, err := t.r.Read(p)
+	t.read += int64(n)
+	expected := time.Duration(t.read * int64(time.Second) / t.rate)
+	if wait := expected - time.Since(t.start); wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
+}
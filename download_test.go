@@ -0,0 +1,82 @@
+package oui
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Serve the registries from the test files, instead of downloading them.
+type fileTransport map[string]string
+
+func (f fileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b, err := os.ReadFile(f[req.URL.String()])
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Body:       io.NopCloser(strings.NewReader(string(b))),
+		Request:    req,
+	}, nil
+}
+
+func testDownloader() *Downloader {
+	t := fileTransport{
+		RegistryURLs[RegistryMAL]: "testdata/oui.txt",
+		RegistryURLs[RegistryMAM]: "testdata/mam.txt",
+		RegistryURLs[RegistryMAS]: "testdata/oui36.txt",
+	}
+	return &Downloader{Client: &http.Client{Transport: t}, Retries: -1}
+}
+
+func TestOpenRegistries(t *testing.T) {
+	var all ParseReport
+	registries := []Registry{RegistryMAL, RegistryMAM, RegistryMAS}
+	db, err := testDownloader().OpenRegistries(registries, WithParseReport(&all))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mal, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mal.Len() + 6; db.Len() != want || all.Records != want {
+		t.Errorf("Len = %d with %d records, want %d", db.Len(), all.Records, want)
+	}
+	// The MA-M and MA-S entries don't replace the MA-L entry of the OUI.
+	e, err := db.LookUp(HardwareAddr{0x00, 0x55, 0xda})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != registrationAuthority || e.Source != string(RegistryMAL) {
+		t.Errorf("LookUp = %q from %s, want the MA-L entry", e.Manufacturer, e.Source)
+	}
+	e, err = db.LookUpUint64(0x70b3d5f57abc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != "Aeronautics Ltd." || e.Source != string(RegistryMAS) {
+		t.Errorf("LookUpUint64 = %q from %s, want the MA-S entry", e.Manufacturer, e.Source)
+	}
+
+	// The limit is for all registries together.
+	db, err = testDownloader().OpenRegistries(registries, WithMaxEntries(mal.Len()+2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != mal.Len()+2 {
+		t.Errorf("Len with a limit = %d, want %d", db.Len(), mal.Len()+2)
+	}
+	// The MA-S entries are past the limit, so only the parent is found.
+	e, err = db.LookUpUint64(0x70b3d50e0123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != registrationAuthority {
+		t.Errorf("LookUpUint64 = %q, want the parent of the MA-S entries past the limit", e.Manufacturer)
+	}
+}
@@ -1,27 +1,65 @@
 package oui
 
 import (
+	"hash/fnv"
 	"strings"
+	"time"
 )
 
 //go:generate: ffjson -nodecoder $(GOFILE)
 
-// A database Entry the represents the data in the oui database.
-// Local and Multicast
+// Entry is an assignment in the oui database.
+// Most fields are read from the registry. Confidence and ParentOrganization
+// are set by lookups.
 type Entry struct {
-	Manufacturer string       `json:"manufacturer"`
-	Address      []string     `json:"address"`
-	Prefix       HardwareAddr `json:"prefix"`
-	Country      string       `json:"country,omitempty"`
-	Local        bool         `json:"local,omitempty"`
-	Multicast    bool         `json:"multicast,omitempty"`
+	Manufacturer string `json:"manufacturer"`
+	// The lines of the address. It is nil if the database was opened
+	// with WithJoinedAddresses, see AddressLines.
+	Address []string `json:"address"`
+	// The first 24 bits of the assignment.
+	Prefix HardwareAddr `json:"prefix"`
+	// The number of bits assigned, if the source specifies it with a mask
+	// or a range. See Assignment.
+	PrefixLen int `json:"prefix_len,omitempty"`
+	// The bits after the first 24 of MA-M and MA-S assignments,
+	// like f0:00:00 for 70:b3:d5:f0:00:00/36.
+	Extension HardwareAddr `json:"extension,omitempty"`
+	Country   string       `json:"country,omitempty"`
+	// The locally administered and multicast bits of the prefix.
+	Local     bool `json:"local,omitempty"`
+	Multicast bool `json:"multicast,omitempty"`
+	// Set for registrations where the assignee has requested to be kept private.
+	// The Manufacturer will be "PRIVATE".
+	IsPrivate bool `json:"private,omitempty"`
+	// A label for where the entry was read from, given with WithSource.
+	Source string `json:"source,omitempty"`
+	// Set by lookups. See Confidence for the scoring.
+	Confidence Confidence `json:"confidence,omitempty"`
+	// The registration or update date, if the source has it.
+	// Only the CSV format has dates, so it is usually the zero time,
+	// which is left out of the JSON encoding.
+	Registered time.Time `json:"registered,omitempty"`
+	// Labels attached by custom registries, like "IoT".
+	Tags []string `json:"tags,omitempty"`
+	// The identifier of the registration in IEEE datasets, if the source has it.
+	RegistrationID string `json:"registration_id,omitempty"`
+
+	// The organization owning the manufacturer, set by lookups
+	// if a mapping has been given with SetParentMapping.
+	ParentOrganization string `json:"parent_organization,omitempty"`
+
+	// The address lines separated by '\n', if WithJoinedAddresses was given.
+	address string
+	// The text the entry was read from, if WithSourceLines was given.
+	sourceLine string
+	// The normalizer of the database the entry was returned from, if any.
+	normalizer func(string) string
 }
 
 // Returns a formatted string representation of the entry
 func (e Entry) String() string {
 	t := []string{"Prefix: " + e.Prefix.String(), "Manufacturer: " + e.Manufacturer}
-	if len(e.Address) > 0 {
-		a := strings.Join(e.Address, "\n\t")
+	if a := e.AddressString("\n\t"); a != "" {
 		t = append(t, "Address:", "\t"+a)
 	}
 	if e.Local {
@@ -30,5 +68,181 @@ func (e Entry) String() string {
 	if e.Multicast {
 		t = append(t, "* Multicast")
 	}
+	if e.IsPrivate {
+		t = append(t, "* Private")
+	}
 	return strings.Join(t, "\n")
 }
+
+// SourceLine returns the text the entry was read from, including the
+// address lines, if the database was opened with WithSourceLines.
+// Otherwise an empty string is returned.
+func (e Entry) SourceLine() string {
+	return e.sourceLine
+}
+
+// HasTag returns true if the entry has the tag.
+// Tags are compared case insensitively.
+func (e Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddressString returns the non-empty address lines joined by sep.
+// An empty string is returned if the entry has no address.
+// If the database was opened with WithJoinedAddresses,
+// AddressString("\n") returns the stored address without allocating.
+func (e Entry) AddressString(sep string) string {
+	if e.Address == nil && e.address != "" {
+		if sep == "\n" {
+			return e.address
+		}
+		return strings.ReplaceAll(e.address, "\n", sep)
+	}
+	lines := make([]string, 0, len(e.Address))
+	for _, a := range e.Address {
+		if strings.TrimSpace(a) != "" {
+			lines = append(lines, a)
+		}
+	}
+	return strings.Join(lines, sep)
+}
+
+// AddressLines returns the lines of the address.
+// This is Address, unless the database was opened with WithJoinedAddresses,
+// where the lines are split from the stored address when they are needed.
+func (e Entry) AddressLines() []string {
+	if e.Address == nil && e.address != "" {
+		return strings.Split(e.address, "\n")
+	}
+	return e.Address
+}
+
+// Store the address as a single string, see WithJoinedAddresses.
+// The country shares memory with the address, if it is the last line.
+func (e *Entry) joinAddress() {
+	if e.Address == nil {
+		return
+	}
+	shared := e.Country != "" && e.Country == e.Address[len(e.Address)-1]
+	e.address, e.Address = e.AddressString("\n"), nil
+	if shared && e.Country == e.lastAddressLine() {
+		e.Country = e.lastAddressLine()
+	}
+}
+
+// The last line of an address joined by joinAddress.
+func (e Entry) lastAddressLine() string {
+	return e.address[strings.LastIndexByte(e.address, '\n')+1:]
+}
+
+// The number of bits assigned. Entries without a
+// known prefix length are considered to be 24 bits.
+func (e Entry) bits() int {
+	if e.PrefixLen == 0 {
+		return 24
+	}
+	return e.PrefixLen
+}
+
+// Assignment returns the assigned prefix, made from the Prefix, the Extension
+// and the number of bits assigned, so 70:b3:d5:f0:00:00/36 for an MA-S entry.
+// Entries without a known prefix length are considered to be 24 bits.
+func (e Entry) Assignment() Prefix {
+	p := Prefix{Addr: [6]byte{e.Prefix[0], e.Prefix[1], e.Prefix[2], e.Extension[0], e.Extension[1], e.Extension[2]}, Bits: e.bits()}
+	return p.masked()
+}
+
+// NormalizedManufacturer returns the manufacturer case folded,
+// with leading and trailing whitespace removed and other whitespace
+// replaced by a single space, so names can be compared.
+// If a normalizer has been set with SetNameNormalizer on the database
+// the entry was returned from, it is used instead.
+func (e Entry) NormalizedManufacturer() string {
+	if e.normalizer != nil {
+		return e.normalizer(e.Manufacturer)
+	}
+	return searchOptions{}.normalize(strings.Join(strings.Fields(e.Manufacturer), " "))
+}
+
+// BlockSize returns the number of MAC addresses covered by the assignment,
+// so 2^24 for MA-L, 2^20 for MA-M and 2^12 for MA-S assignments.
+// Entries without a known prefix length are considered to be 24 bits.
+func (e Entry) BlockSize() uint64 {
+	return 1 << uint(48-e.bits())
+}
+
+// Equal returns true if both entries have the same content.
+// The Source, Confidence and ParentOrganization of the entries are not compared,
+// since they are not part of the registration.
+// Two nil entries are equal, but a nil entry is never equal to a non-nil entry.
+func (e *Entry) Equal(other *Entry) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	if e.Prefix != other.Prefix || e.PrefixLen != other.PrefixLen || e.Extension != other.Extension || e.Manufacturer != other.Manufacturer || e.Country != other.Country {
+		return false
+	}
+	if e.Local != other.Local || e.Multicast != other.Multicast || e.IsPrivate != other.IsPrivate {
+		return false
+	}
+	if !e.Registered.Equal(other.Registered) || e.RegistrationID != other.RegistrationID {
+		return false
+	}
+	return equalStrings(e.AddressLines(), other.AddressLines()) && equalStrings(e.Tags, other.Tags)
+}
+
+// Returns true if a and b contain the same strings in the same order.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns a hash of the content of the entry.
+// Entries that are Equal will have the same hash, so Source, Confidence
+// and ParentOrganization are not hashed.
+// A nil entry hashes to 0.
+func (e *Entry) Hash() uint64 {
+	if e == nil {
+		return 0
+	}
+	h := fnv.New64a()
+	// Strings are terminated by a zero byte, so fields cannot run into each other.
+	h.Write(e.Prefix[:])
+	h.Write([]byte{byte(e.PrefixLen)})
+	h.Write(e.Extension[:])
+	h.Write([]byte(e.Manufacturer + "\x00" + e.Country + "\x00"))
+	for _, a := range e.AddressLines() {
+		h.Write([]byte(a + "\x00"))
+	}
+	var flags byte
+	if e.Local {
+		flags |= 1
+	}
+	if e.Multicast {
+		flags |= 2
+	}
+	if e.IsPrivate {
+		flags |= 4
+	}
+	h.Write([]byte{flags})
+	if !e.Registered.IsZero() {
+		h.Write([]byte(e.Registered.UTC().Format(time.RFC3339Nano)))
+	}
+	for _, t := range e.Tags {
+		h.Write([]byte(t + "\x00"))
+	}
+	h.Write([]byte(e.RegistrationID))
+	return h.Sum64()
+}
@@ -0,0 +1,41 @@
+package oui
+
+// EUI64 is a 64 bit extended unique identifier.
+type EUI64 [8]byte
+
+// ToEUI64 will expand a 48 bit MAC address to the EUI-64 form,
+// by inserting FF-FE between the OUI and the rest of the address,
+// so 00-11-22-33-44-55 becomes 00-11-22-FF-FE-33-44-55.
+// A HardwareAddr only holds the OUI, so the full address must be given.
+// Use ParsePrefix to parse a complete address.
+func ToEUI64(mac [6]byte) EUI64 {
+	return EUI64{mac[0], mac[1], mac[2], 0xff, 0xfe, mac[3], mac[4], mac[5]}
+}
+
+// ToEUI64Modified will expand a 48 bit MAC address like ToEUI64,
+// and invert the universal/local bit, as used for IPv6 interface
+// identifiers described in RFC 4291, appendix A.
+// So 34-56-78-9A-BC-DE becomes 36-56-78-FF-FE-9A-BC-DE.
+func ToEUI64Modified(mac [6]byte) EUI64 {
+	e := ToEUI64(mac)
+	e[0] ^= 2
+	return e
+}
+
+// OUI returns the first 24 bits of the identifier.
+func (e EUI64) OUI() HardwareAddr {
+	return HardwareAddr{e[0], e[1], e[2]}
+}
+
+// String returns the identifier as "xx:xx:xx:xx:xx:xx:xx:xx" with lowercase hex digits.
+func (e EUI64) String() string {
+	const digits = "0123456789abcdef"
+	b := make([]byte, 0, 23)
+	for i, v := range e {
+		if i > 0 {
+			b = append(b, ':')
+		}
+		b = append(b, digits[v>>4], digits[v&15])
+	}
+	return string(b)
+}
@@ -0,0 +1,29 @@
+package oui
+
+import "testing"
+
+func TestToEUI64(t *testing.T) {
+	tests := []struct {
+		mac             [6]byte
+		eui64, modified string
+	}{
+		// The example of RFC 2464, section 4, which uses the mapping of RFC 4291, appendix A.
+		{mac: [6]byte{0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde}, eui64: "34:56:78:ff:fe:9a:bc:de", modified: "36:56:78:ff:fe:9a:bc:de"},
+		// A universally administered address gets the bit set.
+		{mac: [6]byte{0x00, 0xaa, 0x00, 0x3f, 0x2a, 0x1c}, eui64: "00:aa:00:ff:fe:3f:2a:1c", modified: "02:aa:00:ff:fe:3f:2a:1c"},
+		// A locally administered address gets the bit cleared.
+		{mac: [6]byte{0x02, 0x00, 0x5e, 0x10, 0x00, 0x01}, eui64: "02:00:5e:ff:fe:10:00:01", modified: "00:00:5e:ff:fe:10:00:01"},
+	}
+	for _, test := range tests {
+		if got := ToEUI64(test.mac).String(); got != test.eui64 {
+			t.Errorf("ToEUI64(%x) = %s, want %s", test.mac, got, test.eui64)
+		}
+		e := ToEUI64Modified(test.mac)
+		if got := e.String(); got != test.modified {
+			t.Errorf("ToEUI64Modified(%x) = %s, want %s", test.mac, got, test.modified)
+		}
+		if e.OUI() != (HardwareAddr{test.mac[0] ^ 2, test.mac[1], test.mac[2]}) {
+			t.Errorf("OUI of %s = %s", e, e.OUI())
+		}
+	}
+}
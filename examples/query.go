@@ -5,6 +5,7 @@ package main
 // To run, execute: go run query.go
 
 import (
+	"errors"
 	"fmt"
 	"github.com/klauspost/oui"
 )
@@ -19,7 +20,7 @@ func main() {
 
 	// Query on text string
 	entry, err := db.Query("00-60-93-98-02-01")
-	if err == oui.ErrNotFound {
+	if errors.Is(err, oui.ErrNotFound) {
 		fmt.Println("Not found")
 	} else if err != nil {
 		panic(err)
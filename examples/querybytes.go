@@ -5,6 +5,7 @@ package main
 // To run, execute: go run querybytes.go
 
 import (
+	"errors"
 	"fmt"
 	"github.com/klauspost/oui"
 )
@@ -22,7 +23,7 @@ func main() {
 
 	// Now we look up
 	entry, err := db.LookUp(hw)
-	if err == oui.ErrNotFound {
+	if errors.Is(err, oui.ErrNotFound) {
 		fmt.Println("Not found")
 	} else if err != nil {
 		panic(err)
@@ -0,0 +1,131 @@
+package oui
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The time format of the "Generated:" line in oui.txt.
+const generatedFormat = "Mon, 2 Jan 2006 15:04:05 -0700"
+
+// ExportFiltered will write the entries matching pred to w in the given format,
+// sorted by prefix. If pred is nil, all entries are written.
+// FormatOUI, FormatManuf, FormatCSV and FormatJSONLines can be written,
+// other formats will return ErrUnsupportedFormat.
+func ExportFiltered(db OuiDB, w io.Writer, pred func(*Entry) bool, format Format) error {
+	var entries []*Entry
+	err := db.walk(func(e Entry) bool {
+		if pred == nil || pred(&e) {
+			entries = append(entries, &e)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sortEntries(entries)
+	bw := bufio.NewWriter(w)
+	switch format {
+	case FormatJSONLines:
+		for _, e := range entries {
+			j, err := e.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			bw.Write(j)
+			bw.WriteByte('\n')
+		}
+	case FormatOUI:
+		if t := db.Generated(); !t.IsZero() {
+			fmt.Fprintf(bw, "Generated: %s\n\n", t.Format(generatedFormat))
+		}
+		for _, e := range entries {
+			writeOUIEntry(bw, e)
+		}
+	case FormatManuf:
+		for _, e := range entries {
+			p := strings.ToUpper(e.Prefix.String())
+			if e.PrefixLen != 0 {
+				p = fmt.Sprintf("%s:%s/%d", p, strings.ToUpper(e.Extension.String()), e.PrefixLen)
+			}
+			fmt.Fprintf(bw, "%s\t%s\n", p, e.Manufacturer)
+		}
+	case FormatCSV:
+		// Dates, tags and registration IDs are only written if any entry has them.
+		dates, tags, ids := false, false, false
+		for _, e := range entries {
+			dates = dates || !e.Registered.IsZero()
+			tags = tags || len(e.Tags) > 0
+			ids = ids || e.RegistrationID != ""
+		}
+		cw := csv.NewWriter(bw)
+		header := []string{"Registry", "Assignment", "Organization Name", "Organization Address"}
+		if dates {
+			header = append(header, "Date Registered")
+		}
+		if tags {
+			header = append(header, "Tags")
+		}
+		if ids {
+			header = append(header, "Registration ID")
+		}
+		cw.Write(header)
+		for _, e := range entries {
+			rec := []string{string(e.Registry()), csvAssignment(e), e.Manufacturer, e.AddressString(" ")}
+			if dates {
+				d := ""
+				if !e.Registered.IsZero() {
+					d = e.Registered.Format("2006-01-02")
+				}
+				rec = append(rec, d)
+			}
+			if tags {
+				rec = append(rec, strings.Join(e.Tags, csvTagSeparator))
+			}
+			if ids {
+				rec = append(rec, e.RegistrationID)
+			}
+			cw.Write(rec)
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	default:
+		return ErrUnsupportedFormat
+	}
+	return bw.Flush()
+}
+
+// The hex digits of the assignment of an entry in the CSV format,
+// so 7 digits for MA-M and 9 digits for MA-S assignments.
+func csvAssignment(e *Entry) string {
+	a := e.Assignment()
+	digits := 6
+	if bits := e.bits(); bits > 24 {
+		digits = (bits + 3) / 4
+	}
+	return strings.ToUpper(hex.EncodeToString(a.Addr[:]))[:digits]
+}
+
+// Write an entry in the oui.txt format.
+// MA-M and MA-S entries are written like mam.txt and oui36.txt,
+// with the range of the assignment after the OUI on the "(base 16)" line.
+func writeOUIEntry(w *bufio.Writer, e *Entry) {
+	base16 := strings.ToUpper(strings.Replace(e.Prefix.String(), ":", "", -1))
+	if bits := e.bits(); bits > 24 && bits <= 48 {
+		a := e.Assignment()
+		start := uint32(a.Addr[3])<<16 | uint32(a.Addr[4])<<8 | uint32(a.Addr[5])
+		base16 = fmt.Sprintf("%06X-%06X", start, start+uint32(e.BlockSize())-1)
+	}
+	fmt.Fprintf(w, "%s   (hex)\t\t%s\n", e.Prefix.OUIString(), e.Manufacturer)
+	fmt.Fprintf(w, "%s     (base 16)\t\t%s\n", base16, e.Manufacturer)
+	for _, a := range e.AddressLines() {
+		fmt.Fprintf(w, "\t\t\t\t%s\n", a)
+	}
+	w.WriteByte('\n')
+}
@@ -0,0 +1,97 @@
+package oui
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+)
+
+// Format is a file format containing registry data.
+type Format int
+
+const (
+	// FormatUnknown is returned when the format cannot be detected.
+	FormatUnknown Format = iota
+	// FormatOUI is the oui.txt format published by the IEEE.
+	FormatOUI
+	// FormatCSV is the CSV format published by the IEEE.
+	FormatCSV
+	// FormatManuf is the Wireshark "manuf" format.
+	FormatManuf
+	// FormatJSONLines is newline delimited JSON entries, as written by WriteJSONLines.
+	FormatJSONLines
+	// FormatSQLite is a SQLite database, as written by the ouisqlite package.
+	FormatSQLite
+	// FormatGzip is gzip compressed content of another format.
+	FormatGzip
+)
+
+// String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case FormatOUI:
+		return "oui.txt"
+	case FormatCSV:
+		return "csv"
+	case FormatManuf:
+		return "manuf"
+	case FormatJSONLines:
+		return "jsonl"
+	case FormatSQLite:
+		return "sqlite"
+	case FormatGzip:
+		return "gzip"
+	}
+	return "unknown"
+}
+
+// ErrUnsupportedFormat is returned when content in a format
+// that cannot be read is given.
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// The number of bytes inspected by DetectFormat.
+const sniffLen = 4096
+
+var (
+	sqliteMagic = []byte("SQLite format 3\x00")
+	gzipMagic   = []byte{0x1f, 0x8b}
+	csvHeader   = []byte("Registry,Assignment,")
+	ouiMarkers  = regexp.MustCompile(`(?i)\((hex|base 16)\)`)
+	manufLine   = regexp.MustCompile(`(?m)^[0-9A-Fa-f]{2}[:\-.][0-9A-Fa-f]{2}[:\-.][0-9A-Fa-f]{2}\S*\s`)
+)
+
+// DetectFormat will read the start of r and detect the format of the content.
+// A reader returning the entire content, including the bytes inspected, is returned.
+// If the format cannot be detected FormatUnknown is returned.
+func DetectFormat(r io.Reader) (Format, io.Reader, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return FormatUnknown, nil, err
+	}
+	head = head[:n]
+	return detect(head), io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// Detect the format from the start of the content.
+func detect(head []byte) Format {
+	switch {
+	case bytes.HasPrefix(head, sqliteMagic):
+		return FormatSQLite
+	case bytes.HasPrefix(head, gzipMagic):
+		return FormatGzip
+	}
+	trimmed := bytes.TrimLeft(head, " \t\r\n\ufeff")
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatJSONLines
+	case bytes.HasPrefix(trimmed, csvHeader):
+		return FormatCSV
+	case ouiMarkers.Match(head):
+		return FormatOUI
+	case manufLine.Match(head):
+		return FormatManuf
+	}
+	return FormatUnknown
+}
@@ -0,0 +1,93 @@
+package oui
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// LookUpFuzzy will look up a Mac address that may have been mistyped.
+// If s can be parsed and is found, only that entry is returned.
+// Otherwise the prefixes a single wrong, extra or missing hex digit away
+// from the start of s are looked up, and the entries found are returned as
+// suggestions, sorted by prefix. Suggestions have ConfidenceVeryLow.
+// If there are no suggestions, the error from parsing or looking up s is returned.
+func LookUpFuzzy(db ReadOnlyDB, s string) ([]*Entry, error) {
+	hw, err := ParseMac(s)
+	if err == nil {
+		var e *Entry
+		if e, err = db.LookUp(*hw); err == nil {
+			return []*Entry{e}, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	var digits []byte
+	for i := 0; i < len(s); i++ {
+		if isHexDigit(s[i]) {
+			digits = append(digits, s[i])
+		}
+	}
+	seen := make(map[HardwareAddr]struct{})
+	var res []*Entry
+	for _, c := range fuzzyPrefixes(digits) {
+		b, herr := hex.DecodeString(c)
+		if herr != nil {
+			continue
+		}
+		p := HardwareAddr{b[0], b[1], b[2]}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		e, lerr := db.LookUp(p)
+		if lerr != nil {
+			if !errors.Is(lerr, ErrNotFound) {
+				return nil, lerr
+			}
+			continue
+		}
+		e.Confidence = ConfidenceVeryLow
+		res = append(res, e)
+	}
+	if len(res) == 0 {
+		return nil, err
+	}
+	sortEntries(res)
+	return res, nil
+}
+
+// Return the 6 digit prefixes a single substitution, deletion or insertion
+// away from the start of digits.
+func fuzzyPrefixes(digits []byte) []string {
+	const hexDigits = "0123456789abcdef"
+	d := strings.ToLower(string(digits))
+	var res []string
+	if len(d) >= 6 {
+		base := d[:6]
+		for i := 0; i < 6; i++ {
+			for _, c := range hexDigits {
+				if byte(c) != base[i] {
+					res = append(res, base[:i]+string(c)+base[i+1:])
+				}
+			}
+		}
+	}
+	if len(d) >= 7 {
+		// A digit too many.
+		for i := 0; i < 7; i++ {
+			res = append(res, d[:i]+d[i+1:7])
+		}
+	}
+	if len(d) >= 5 {
+		// A missing digit.
+		short := d[:5]
+		for i := 0; i <= 5; i++ {
+			for _, c := range hexDigits {
+				res = append(res, short[:i]+string(c)+short[i:])
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,10 @@
+module github.com/klauspost/oui
+
+go 1.26.0
+
+require (
+	github.com/gorhill/cronexpr v0.0.0-20180427100037-88b0669f7d75
+	github.com/mattn/go-sqlite3 v1.14.52
+	github.com/pquerna/ffjson v0.0.0-20190930134022-aa0246cd15f7
+	golang.org/x/text v0.42.0
+)
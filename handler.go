@@ -0,0 +1,400 @@
+package oui
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HandlerOption is an option for NewHTTPHandler.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	origin   string
+	maxBatch int
+	maxAge   time.Duration
+	clock    Clock
+	// Set by WithRateLimit, the limiter is created with the clock.
+	rateLimit bool
+	perSecond float64
+	burst     int
+	limiter   *rateLimiter
+}
+
+// The default maximum number of addresses in a batch request.
+const defaultMaxBatch = 1000
+
+// WithOrigin will set the value sent in the "Access-Control-Allow-Origin" header.
+// By default the header is not sent.
+func WithOrigin(origin string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.origin = origin
+	}
+}
+
+// WithMaxBatch will set the maximum number of addresses accepted in a batch request.
+// The default is 1000.
+func WithMaxBatch(n int) HandlerOption {
+	return func(o *handlerOptions) {
+		if n > 0 {
+			o.maxBatch = n
+		}
+	}
+}
+
+// WithRateLimit will limit the number of requests from each remote IP
+// to perSecond on average, allowing bursts of up to burst requests.
+// Requests exceeding the limit get a 429 Too Many Requests response.
+// The remote IP is taken from the connection, so a proxy in front of
+// the handler will be limited as a single client.
+func WithRateLimit(perSecond float64, burst int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.rateLimit, o.perSecond, o.burst = true, perSecond, burst
+	}
+}
+
+// WithHandlerClock will use clock for the rate limit and the age check of
+// the "/healthz" endpoint. By default SystemClock is used.
+func WithHandlerClock(clock Clock) HandlerOption {
+	return func(o *handlerOptions) {
+		o.clock = clock
+	}
+}
+
+// WithMaxAge will make the "/healthz" endpoint report the database as not ready
+// if it was generated more than maxAge ago, or if the generation time is unknown.
+// By default the age of the database is not checked.
+func WithMaxAge(maxAge time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		o.maxAge = maxAge
+	}
+}
+
+// A single lookup result.
+type handlerResponse struct {
+	Mac   string `json:"mac,omitempty"`
+	Data  *Entry `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type handler struct {
+	db   ReadOnlyDB
+	opts handlerOptions
+}
+
+// NewHTTPHandler will return a handler serving lookups from db.
+//
+// A GET request looks up the address given as the "mac" parameter,
+// or as the path, for instance "/D0-DF-9A". The response is a JSON object
+// with the entry as "data", or an "error" if the address is not found (404)
+// or cannot be parsed (400).
+// The response has an ETag made from the generation time of the database,
+// and requests with a matching If-None-Match header get a 304 Not Modified response.
+// Clients accepting gzip get another ETag, and all responses have
+// "Vary: Accept-Encoding".
+//
+// A POST request looks up a batch of addresses, given as a JSON array of strings.
+// The response is a JSON array with a result for each address in the same order.
+// Each result has the address as "mac", and either "data" or "error",
+// so a single invalid address doesn't fail the batch.
+//
+// A GET request to "/export" returns all entries as a JSON array, in no particular order.
+// The entries are written as they are encoded, so the response is sent with chunked
+// transfer encoding, and neither the entries nor the encoded database are held in memory.
+// A HEAD request to "/export" only sends the headers.
+//
+// A GET request to "/healthz" reports whether the database is ready.
+// It returns 200 if the database contains entries and isn't older than
+// allowed by WithMaxAge, and 503 otherwise. The response is a JSON object
+// with the generation time, the time the database was loaded and the number
+// of entries, if the database provides them.
+func NewHTTPHandler(db ReadOnlyDB, opts ...HandlerOption) http.Handler {
+	h := &handler{db: db, opts: handlerOptions{maxBatch: defaultMaxBatch}}
+	for _, o := range opts {
+		o(&h.opts)
+	}
+	if h.opts.clock == nil {
+		h.opts.clock = SystemClock
+	}
+	if h.opts.rateLimit {
+		h.opts.limiter = newRateLimiter(h.opts.perSecond, h.opts.burst, h.opts.clock)
+	}
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.opts.origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", h.opts.origin)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	// Set on all responses, also those that aren't compressed, and 304 responses.
+	w.Header().Set("Vary", "Accept-Encoding")
+	if r.URL.Path == "/healthz" {
+		h.serveHealth(w, r)
+		return
+	}
+	if h.opts.limiter != nil && !h.opts.limiter.allow(r.RemoteAddr) {
+		h.write(w, r, http.StatusTooManyRequests, handlerResponse{Error: "rate limit exceeded"})
+		return
+	}
+	w.Header().Set("Last-Modified", h.db.Generated().Format(http.TimeFormat))
+	if r.URL.Path == "/export" && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+		h.serveExport(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.serveLookUp(w, r)
+	case http.MethodPost:
+		h.serveBatch(w, r)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		h.write(w, r, http.StatusMethodNotAllowed, handlerResponse{Error: "method not allowed"})
+	}
+}
+
+// Look up a single address.
+func (h *handler) serveLookUp(w http.ResponseWriter, r *http.Request) {
+	mac := r.URL.Query().Get("mac")
+	if mac == "" {
+		mac = strings.Trim(r.URL.Path, "/")
+	}
+	if hw, err := ParseMac(mac); err == nil {
+		if tag := h.etag(*hw, acceptsGzip(r)); tag != "" {
+			w.Header().Set("ETag", tag)
+			if etagMatch(r.Header.Get("If-None-Match"), tag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+	res, status := h.lookUp(r.Context(), mac)
+	res.Mac = ""
+	h.write(w, r, status, res)
+}
+
+// The status of the database.
+type health struct {
+	Ready     bool       `json:"ready"`
+	Generated *time.Time `json:"generated,omitempty"`
+	LoadedAt  *time.Time `json:"loaded_at,omitempty"`
+	Len       *int       `json:"len,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Report whether the database is ready.
+func (h *handler) serveHealth(w http.ResponseWriter, r *http.Request) {
+	var res health
+	if t := h.db.Generated(); !t.IsZero() {
+		res.Generated = &t
+	}
+	if db, ok := h.db.(interface{ LoadedAt() time.Time }); ok {
+		t := db.LoadedAt()
+		res.LoadedAt = &t
+	}
+	if db, ok := h.db.(interface{ Len() int }); ok {
+		n := db.Len()
+		res.Len = &n
+	}
+	switch {
+	case res.Len != nil && *res.Len == 0:
+		res.Error = "database is empty"
+	case h.opts.maxAge > 0 && IsStale(h.db, h.opts.maxAge, h.opts.clock):
+		res.Error = "database is stale"
+	default:
+		res.Ready = true
+	}
+	status := http.StatusOK
+	if !res.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	h.write(w, r, status, res)
+}
+
+// Return the ETag of the response for a hardware address.
+// The content only changes when the database is replaced,
+// so the tag is made from the generation time of the database.
+// Responses that can be compressed have their own tag,
+// since the tag is strong and the content is different.
+// If the generation time is unknown, no tag is returned.
+func (h *handler) etag(hw HardwareAddr, gzipped bool) string {
+	t := h.db.Generated()
+	if t.IsZero() {
+		return ""
+	}
+	tag := strconv.FormatInt(t.UnixNano(), 36) + "-" + hex.EncodeToString(hw[:])
+	if gzipped {
+		tag += "-gzip"
+	}
+	return `"` + tag + `"`
+}
+
+// Returns true if the If-None-Match header matches the tag.
+func etagMatch(header, tag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == tag || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Entries are flushed to the client for every exportFlushEvery entries.
+const exportFlushEvery = 1000
+
+// Stream all entries as a JSON array.
+// The entries are written as they are iterated, so they are never collected.
+// Iterate uses a snapshot, so the lock of the database isn't held
+// while writing to a slow client.
+func (h *handler) serveExport(w http.ResponseWriter, r *http.Request) {
+	db, ok := h.db.(interface{ Iterate(func(*Entry) bool) error })
+	if !ok {
+		h.write(w, r, http.StatusNotImplemented, handlerResponse{Error: "export not supported by database"})
+		return
+	}
+	gzipped := acceptsGzip(r)
+	if gzipped {
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+	var out io.Writer = w
+	flush := func() {}
+	if gzipped {
+		gw := gzip.NewWriter(w)
+		defer gw.Close()
+		out = gw
+		flush = func() { gw.Flush() }
+	}
+	if f, ok := w.(http.Flusher); ok {
+		inner := flush
+		flush = func() {
+			inner()
+			f.Flush()
+		}
+	}
+	enc := json.NewEncoder(out)
+	io.WriteString(out, "[")
+	n := 0
+	var werr error
+	err := db.Iterate(func(e *Entry) bool {
+		if n > 0 {
+			io.WriteString(out, ",")
+		}
+		if werr = enc.Encode(e); werr != nil {
+			return false
+		}
+		n++
+		if n%exportFlushEvery == 0 {
+			flush()
+		}
+		return true
+	})
+	if err != nil || werr != nil {
+		// The status has been sent, so the client will get invalid JSON.
+		return
+	}
+	io.WriteString(out, "]\n")
+}
+
+// Look up a JSON array of addresses.
+func (h *handler) serveBatch(w http.ResponseWriter, r *http.Request) {
+	var macs []string
+	// Allow generous room per address, but not unbounded bodies.
+	body := io.LimitReader(r.Body, int64(h.opts.maxBatch)*64+1024)
+	if err := json.NewDecoder(body).Decode(&macs); err != nil {
+		h.write(w, r, http.StatusBadRequest, handlerResponse{Error: "invalid batch: " + err.Error()})
+		return
+	}
+	if len(macs) > h.opts.maxBatch {
+		h.write(w, r, http.StatusRequestEntityTooLarge, handlerResponse{Error: "too many addresses in batch"})
+		return
+	}
+	res := make([]handlerResponse, len(macs))
+	for i, mac := range macs {
+		res[i], _ = h.lookUp(r.Context(), mac)
+	}
+	h.write(w, r, http.StatusOK, res)
+}
+
+// Look up an address, and return the result and the status to send for it.
+func (h *handler) lookUp(ctx context.Context, mac string) (handlerResponse, int) {
+	res := handlerResponse{Mac: mac}
+	hw, err := ParseMac(mac)
+	if err != nil {
+		res.Error = err.Error()
+		return res, http.StatusBadRequest
+	}
+	var e *Entry
+	if db, ok := h.db.(interface {
+		LookUpContext(context.Context, HardwareAddr) (*Entry, error)
+	}); ok {
+		e, err = db.LookUpContext(ctx, *hw)
+	} else {
+		e, err = h.db.LookUp(*hw)
+	}
+	switch {
+	case errors.Is(err, ErrNotFound):
+		res.Error = "not found in db"
+		return res, http.StatusNotFound
+	case err != nil:
+		res.Error = err.Error()
+		return res, http.StatusInternalServerError
+	}
+	res.Data = e
+	return res, http.StatusOK
+}
+
+// Responses smaller than this are not compressed.
+const minGzipSize = 1024
+
+// Write v as the JSON response.
+// The response is gzip compressed if the client accepts it.
+func (h *handler) write(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(j) < minGzipSize || !acceptsGzip(r) {
+		w.WriteHeader(status)
+		w.Write(j)
+		return
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	w.WriteHeader(status)
+	gw := gzip.NewWriter(w)
+	gw.Write(j)
+	gw.Close()
+}
+
+// Returns true if the request accepts gzip encoded responses.
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		params := strings.Split(enc, ";")
+		if strings.TrimSpace(params[0]) != "gzip" {
+			continue
+		}
+		// Explicitly disabled with "gzip;q=0".
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil && q == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package oui
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	db, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The ETag is made from the generation time, which the test file doesn't have.
+	if err := db.ApplyDelta(strings.NewReader("= 2026-10-14T07:00:00Z\n")); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHTTPHandler(db)
+	serve := func(method, target, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	tests := []struct {
+		target string
+		status int
+	}{
+		{target: "/00-22-72", status: http.StatusOK},
+		{target: "/?mac=00:22:72:01:02:03", status: http.StatusOK},
+		{target: "/12-34-56", status: http.StatusNotFound},
+		{target: "/nonsense", status: http.StatusBadRequest},
+		{target: "/healthz", status: http.StatusOK},
+	}
+	for _, test := range tests {
+		if w := serve(http.MethodGet, test.target, "", nil); w.Code != test.status {
+			t.Errorf("GET %s = %d, want %d: %s", test.target, w.Code, test.status, w.Body)
+		}
+	}
+
+	w := serve(http.MethodGet, "/00-22-72", "", nil)
+	var res handlerResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Data == nil || res.Data.Manufacturer != "American Micro-Fuel Device Corp." {
+		t.Errorf("lookup returned %s", w.Body)
+	}
+	tag := w.Header().Get("ETag")
+	notModified := serve(http.MethodGet, "/00-22-72", "", map[string]string{"If-None-Match": tag})
+	if notModified.Code != http.StatusNotModified {
+		t.Errorf("GET with a matching ETag = %d, want 304", notModified.Code)
+	}
+	// Compressed responses have another tag, and all responses vary by encoding.
+	gzipHeader := map[string]string{"Accept-Encoding": "gzip"}
+	gzipped := serve(http.MethodGet, "/00-22-72", "", gzipHeader)
+	if gzipTag := gzipped.Header().Get("ETag"); gzipTag == "" || gzipTag == tag {
+		t.Errorf("ETag with gzip = %q, want another than %q", gzipTag, tag)
+	}
+	gzipHeader["If-None-Match"] = tag
+	if w := serve(http.MethodGet, "/00-22-72", "", gzipHeader); w.Code != http.StatusOK {
+		t.Errorf("GET with gzip and the identity ETag = %d, want 200", w.Code)
+	}
+	for _, w := range []*httptest.ResponseRecorder{w, notModified, gzipped} {
+		if v := w.Header().Values("Vary"); len(v) != 1 || v[0] != "Accept-Encoding" {
+			t.Errorf("Vary of %d response = %q, want Accept-Encoding", w.Code, v)
+		}
+	}
+
+	w = serve(http.MethodPost, "/", `["00:22:72", "bad", "12:34:56"]`, nil)
+	var batch []handlerResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != 3 || batch[0].Data == nil || batch[1].Error == "" || batch[2].Error == "" {
+		t.Errorf("batch returned %s", w.Body)
+	}
+	if w := serve(http.MethodDelete, "/", "", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE = %d, want 405", w.Code)
+	}
+}
+
+func TestHTTPHandlerExport(t *testing.T) {
+	db, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewHTTPHandler(db))
+	defer srv.Close()
+	get := func(method string, gzipped bool) (*http.Response, []byte) {
+		req, err := http.NewRequest(method, srv.URL+"/export", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gzipped {
+			// Setting the header stops the transport from decompressing the body.
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, b
+	}
+	check := func(b []byte) {
+		t.Helper()
+		var entries []Entry
+		if err := json.Unmarshal(b, &entries); err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != db.Len() {
+			t.Errorf("export has %d entries, want %d", len(entries), db.Len())
+		}
+	}
+
+	resp, b := get(http.MethodGet, false)
+	if resp.Header.Get("Content-Type") != "application/json" || resp.ContentLength != -1 {
+		t.Errorf("export sent as %q with length %d, want chunked JSON", resp.Header.Get("Content-Type"), resp.ContentLength)
+	}
+	check(b)
+
+	resp, b = get(http.MethodGet, true)
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("export not gzipped, encoding %q", resp.Header.Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(b)
+
+	// A HEAD request gets the headers, and no gzip stream.
+	head := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodHead, "/export", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	NewHTTPHandler(db).ServeHTTP(head, req)
+	if head.Code != http.StatusOK || head.Header().Get("Content-Encoding") != "gzip" || head.Body.Len() != 0 {
+		t.Errorf("HEAD /export = %d with %d bytes, encoding %q", head.Code, head.Body.Len(), head.Header().Get("Content-Encoding"))
+	}
+}
@@ -1,7 +1,10 @@
 package oui
 
 import (
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -11,12 +14,29 @@ import (
 type HardwareAddr [3]byte
 
 // String returns a hex string identifying the OUI.
-// This will be as "xx:yy:zz" where elements are separated by ':'
+// This will be as "xx:yy:zz" with lowercase hex digits, where elements are separated by ':'
 // and written in transmission bit order
 func (h HardwareAddr) String() string {
 	return fmt.Sprintf("%02x:%02x:%02x", h[0], h[1], h[2])
 }
 
+// Upper returns the OUI as "XX:YY:ZZ" with uppercase hex digits.
+func (h HardwareAddr) Upper() string {
+	return fmt.Sprintf("%02X:%02X:%02X", h[0], h[1], h[2])
+}
+
+// Lower returns the OUI as "xx:yy:zz" with lowercase hex digits.
+// This is the same as String.
+func (h HardwareAddr) Lower() string {
+	return h.String()
+}
+
+// OUIString returns the OUI as "XX-YY-ZZ" with uppercase hex digits,
+// the way the IEEE writes assignments in oui.txt.
+func (h HardwareAddr) OUIString() string {
+	return fmt.Sprintf("%02X-%02X-%02X", h[0], h[1], h[2])
+}
+
 // This function will return the address as a quoted hex string.
 func (h HardwareAddr) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + h.String() + `"`), nil
@@ -57,21 +77,215 @@ func (e ErrInvalidMac) Error() string {
 }
 
 // ParseMac will parse a string Mac address and return the first 3 entries.
-// It will attempt to find a separator, ':' and '-' supported.
-// If none of these are matched, it will assume there is none.
+// It will attempt to find a separator after the first octet.
+// Any character that isn't a hex digit can be used, like ':', '-', '.' or a space,
+// as long as the same separator is used between all octets.
+// If no separator is found, it will assume there is none.
+// The address must have between 3 and 6 octets.
 func ParseMac(mac string) (*HardwareAddr, error) {
-	// Attempt to find a separator, ':' and '-' supported.
+	b, err := parseOctets(mac, 3)
+	if err != nil {
+		return nil, err
+	}
+	hw := HardwareAddr{}
+	copy(hw[:], b)
+	return &hw, nil
+}
+
+// Notation is the way a Mac address is written.
+type Notation int
+
+const (
+	// NotationColon is octets separated by ':', like "00:11:22:33:44:55".
+	NotationColon Notation = iota
+	// NotationDash is octets separated by '-', like "00-11-22-33-44-55".
+	NotationDash
+	// NotationDotted is groups of 4 digits separated by '.', like "0011.2233.4455".
+	NotationDotted
+	// NotationBare is digits without separators, like "001122334455".
+	NotationBare
+	// NotationSpace is octets separated by spaces, like "00 11 22 33 44 55".
+	NotationSpace
+	// NotationDot is octets separated by '.', like "00.11.22.33.44.55".
+	NotationDot
+	// NotationOther is octets separated by another character, like "00_11_22".
+	// It is formatted like NotationColon.
+	NotationOther
+)
+
+// String returns the name of the notation.
+func (n Notation) String() string {
+	switch n {
+	case NotationColon:
+		return "colon"
+	case NotationDash:
+		return "dash"
+	case NotationDotted:
+		return "dotted"
+	case NotationBare:
+		return "bare"
+	case NotationSpace:
+		return "space"
+	case NotationDot:
+		return "dot"
+	case NotationOther:
+		return "other"
+	}
+	return "unknown"
+}
+
+// Format will return the hardware address written in the notation,
+// so "00:11:22" is written as "00-11-22" with NotationDash
+// and "0011.22" with NotationDotted.
+func (n Notation) Format(h HardwareAddr) string {
+	switch n {
+	case NotationDash:
+		return fmt.Sprintf("%02x-%02x-%02x", h[0], h[1], h[2])
+	case NotationDotted:
+		return fmt.Sprintf("%02x%02x.%02x", h[0], h[1], h[2])
+	case NotationBare:
+		return fmt.Sprintf("%02x%02x%02x", h[0], h[1], h[2])
+	case NotationSpace:
+		return fmt.Sprintf("%02x %02x %02x", h[0], h[1], h[2])
+	case NotationDot:
+		return fmt.Sprintf("%02x.%02x.%02x", h[0], h[1], h[2])
+	}
+	return h.String()
+}
+
+// ParseMacFormat will parse a string Mac address like ParseMac,
+// and also return the notation it was written in.
+// In addition to the notations supported by ParseMac,
+// groups of 4 digits separated by '.' are supported.
+func ParseMacFormat(mac string) (*HardwareAddr, Notation, error) {
+	n := NotationBare
+	switch {
+	case len(mac) > 4 && mac[4] == '.':
+		n = NotationDotted
+		mac = strings.Replace(mac, ".", "", -1)
+	case len(mac) > 2 && mac[2] == ':':
+		n = NotationColon
+	case len(mac) > 2 && mac[2] == '-':
+		n = NotationDash
+	case len(mac) > 2 && mac[2] == ' ':
+		n = NotationSpace
+	case len(mac) > 2 && mac[2] == '.':
+		n = NotationDot
+	case len(mac) > 2 && !isHexDigit(mac[2]):
+		n = NotationOther
+	}
+	hw, err := ParseMac(mac)
+	if err != nil {
+		return nil, n, err
+	}
+	return hw, n, nil
+}
+
+// ParseMacPrefix will parse a Mac address at the start of s, and return it
+// together with the remaining text, so "aabb.ccdd.eeff @ Gi1/0/1" returns
+// aa:bb:cc and " @ Gi1/0/1". Leading whitespace is skipped.
+// All notations supported by ParseMacFormat are accepted.
+// The address must be followed by the end of the string or a character
+// that isn't a hex digit, ':', '-', '.' or the separator of the address.
+// Digits without separators must be an even number of 6 to 12 digits.
+// ParseMac should be used when the string only contains an address.
+func ParseMacPrefix(s string) (*HardwareAddr, string, error) {
+	t := strings.TrimLeft(s, " \t\r\n\v\f")
+	n, sep := leadingMac(t)
+	if n == 0 {
+		return nil, s, ErrInvalidMac{Reason: "No Mac address found at start of string", Mac: s}
+	}
+	rest := t[n:]
+	if rest != "" && (isHexDigit(rest[0]) || strings.IndexByte(":-.", rest[0]) >= 0 || sep != ' ' && rest[0] == sep) {
+		return nil, s, ErrInvalidMac{Reason: "Mac address is followed by " + strconv.Quote(rest[:1]), Mac: s}
+	}
+	hw, _, err := ParseMacFormat(t[:n])
+	if err != nil {
+		return nil, s, err
+	}
+	return hw, rest, nil
+}
+
+// Return the length of the Mac address at the start of s, and its separator.
+// Digits without separators are all included, so ParseMac can reject
+// an odd number or too many of them. 0 is returned if there is no address.
+func leadingMac(s string) (int, byte) {
+	hexDigits := func(s string) bool {
+		for i := 0; i < len(s); i++ {
+			if !isHexDigit(s[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if len(s) >= 14 && s[4] == '.' && s[9] == '.' && hexDigits(s[:4]) && hexDigits(s[5:9]) && hexDigits(s[10:14]) {
+		return 14, '.'
+	}
+	if len(s) < 3 || !hexDigits(s[:2]) {
+		return 0, 0
+	}
+	if sep := s[2]; !isHexDigit(sep) {
+		n, octets := 2, 1
+		for octets < macOctets && n+3 <= len(s) && s[n] == sep && hexDigits(s[n+1:n+3]) {
+			n, octets = n+3, octets+1
+		}
+		if octets < 3 {
+			return 0, 0
+		}
+		return n, sep
+	}
+	n := 0
+	for n < len(s) && isHexDigit(s[n]) {
+		n++
+	}
+	if n < 6 {
+		return 0, 0
+	}
+	return n, 0
+}
+
+// A RADIUS station ID, with a complete Mac address and an optional SSID.
+var stationID = regexp.MustCompile(`^((?:[0-9A-Fa-f]{2}-){5}[0-9A-Fa-f]{2}|(?:[0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}|` +
+	`[0-9A-Fa-f]{4}\.[0-9A-Fa-f]{4}\.[0-9A-Fa-f]{4}|[0-9A-Fa-f]{12})(?::.*)?$`)
+
+// ParseRADIUSStationID will parse the Mac address of a RADIUS Called-Station-Id
+// or Calling-Station-Id attribute, like "AA-BB-CC-DD-EE-FF", "aabbccddeeff",
+// "AABB.CCDD.EEFF" or "AA:BB:CC:DD:EE:FF".
+// Access points often append the SSID after a colon, like
+// "AA-BB-CC-DD-EE-FF:Corp WiFi", which is ignored.
+// Surrounding whitespace and quotes are removed.
+// The complete address must be present, but only the OUI is returned.
+func ParseRADIUSStationID(s string) (*HardwareAddr, error) {
+	id := strings.Trim(strings.TrimSpace(s), `"`)
+	m := stationID.FindStringSubmatch(id)
+	if m == nil {
+		return nil, ErrInvalidMac{Reason: "Not a RADIUS station ID", Mac: s}
+	}
+	hw, _, err := ParseMacFormat(m[1])
+	return hw, err
+}
+
+// The number of octets of a complete Mac address.
+const macOctets = 6
+
+// Parse a string Mac address of 3 to 6 octets, and return up to max of them.
+// All octets are validated, also the ones after max.
+func parseOctets(mac string, max int) ([]byte, error) {
+	// Attempt to find a separator after the first octet.
 	if len(mac) < 6 {
 		return nil, ErrInvalidMac{Reason: "Mac address too short. Should be at least 6 characters", Mac: mac}
 	}
 	var separator *byte
 	var s []string
 
-	if mac[2] == ':' || mac[2] == '-' {
+	if !isHexDigit(mac[2]) {
 		b := mac[2]
 		separator = &b
 		s = strings.Split(mac, string(*separator))
 	} else {
+		if len(mac)%2 == 1 {
+			return nil, ErrInvalidMac{Reason: "Mac address without separators must have an even number of digits", Mac: mac}
+		}
 		for i := 0; i < len(mac)-1; i += 2 {
 			s = append(s, mac[i:i+2])
 		}
@@ -79,20 +293,98 @@ func ParseMac(mac string) (*HardwareAddr, error) {
 	if len(s) < 3 {
 		return nil, ErrInvalidMac{Reason: "Unable to find at least 3 address elements", Mac: mac}
 	}
-	hw := HardwareAddr{}
+	if len(s) > macOctets {
+		return nil, ErrInvalidMac{Reason: fmt.Sprintf("Found %d address elements, at most %d are allowed", len(s), macOctets), Mac: mac}
+	}
+	var octets []byte
 	for i, p := range s {
-		if i >= 3 {
-			break
-		}
 		if len(p) != 2 {
 			return nil, ErrInvalidMac{Reason: fmt.Sprintf("Address element %d (%s) is not 2 characters", i+1, p), Mac: mac}
 		}
-		var b byte
-		n, err := fmt.Sscanf(p, "%x", &b)
-		if n != 1 {
-			return nil, ErrInvalidMac{Reason: fmt.Sprintf("Address element %d (%s) cannot be parsed as hex value: %v", i+1, p, err), Mac: mac}
+		// Both characters must be digits, so "0g" isn't read as 0.
+		if !isHexDigit(p[0]) || !isHexDigit(p[1]) {
+			return nil, ErrInvalidMac{Reason: fmt.Sprintf("Address element %d (%s) cannot be parsed as hex value", i+1, p), Mac: mac}
 		}
-		hw[i] = b
+		b, _ := hex.DecodeString(p)
+		octets = append(octets, b[0])
 	}
-	return &hw, nil
+	if len(octets) > max {
+		octets = octets[:max]
+	}
+	return octets, nil
+}
+
+// Returns true if c is a hex digit.
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// ParseGUID will parse a 64 bit identifier, like an InfiniBand GUID or a
+// Fibre Channel World Wide Name, and return the OUI in the top 24 bits.
+// The GUID must be 16 hex digits, optionally prefixed by "0x".
+// The digits can be separated by ':' or '-', for instance "0002:c903:0001:2345".
+func ParseGUID(s string) (*HardwareAddr, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	digits = strings.NewReplacer(":", "", "-", "").Replace(digits)
+	if len(digits) != 16 {
+		return nil, ErrInvalidMac{Reason: "GUID must be 16 hex digits", Mac: s}
+	}
+	b, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, ErrInvalidMac{Reason: fmt.Sprintf("GUID cannot be parsed as hex value: %v", err), Mac: s}
+	}
+	return &HardwareAddr{b[0], b[1], b[2]}, nil
+}
+
+// OUI24 returns the OUI of a MAC address stored in the lower 48 bits of mac.
+func OUI24(mac uint64) HardwareAddr {
+	return HardwareAddr{byte(mac >> 40), byte(mac >> 32), byte(mac >> 24)}
+}
+
+// HardwareAddr36 is the first 36 bits of a hardware address,
+// the size of an MA-S assignment.
+// The lower 4 bits of the last byte are always zero.
+type HardwareAddr36 [5]byte
+
+// OUI36 returns the first 36 bits of a MAC address stored in the lower 48 bits of mac.
+func OUI36(mac uint64) HardwareAddr36 {
+	return HardwareAddr36{byte(mac >> 40), byte(mac >> 32), byte(mac >> 24), byte(mac >> 16), byte(mac>>8) & 0xf0}
+}
+
+// String returns the address as "xx:xx:xx:xx:x".
+func (h HardwareAddr36) String() string {
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%x", h[0], h[1], h[2], h[3], h[4]>>4)
+}
+
+// OUI returns the first 24 bits of the address.
+func (h HardwareAddr36) OUI() HardwareAddr {
+	return HardwareAddr{h[0], h[1], h[2]}
+}
+
+// Prefix returns the address as a 36 bit prefix.
+func (h HardwareAddr36) Prefix() Prefix {
+	return Prefix{Addr: [6]byte{h[0], h[1], h[2], h[3], h[4] & 0xf0}, Bits: 36}
+}
+
+// NormalizeMacs will parse all addresses in in, and return the distinct
+// hardware addresses in the order they were first seen.
+// The returned errors have the same length as in, and contain the reason
+// an address could not be parsed, or nil if it was parsed.
+func NormalizeMacs(in []string) ([]HardwareAddr, []error) {
+	res := make([]HardwareAddr, 0, len(in))
+	errs := make([]error, len(in))
+	seen := make(map[HardwareAddr]struct{}, len(in))
+	for i, mac := range in {
+		hw, err := ParseMac(strings.TrimSpace(mac))
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if _, ok := seen[*hw]; ok {
+			continue
+		}
+		seen[*hw] = struct{}{}
+		res = append(res, *hw)
+	}
+	return res, errs
 }
@@ -0,0 +1,154 @@
+package oui
+
+import "testing"
+
+func TestParseMacFormat(t *testing.T) {
+	want := HardwareAddr{0xaa, 0xbb, 0xcc}
+	tests := []struct {
+		mac      string
+		notation Notation
+		invalid  bool
+	}{
+		{mac: "aa:bb:cc:dd:ee:ff", notation: NotationColon},
+		{mac: "AA-BB-CC-DD-EE-FF", notation: NotationDash},
+		{mac: "aabb.ccdd.eeff", notation: NotationDotted},
+		{mac: "aabbccddeeff", notation: NotationBare},
+		{mac: "aa bb cc dd ee ff", notation: NotationSpace},
+		{mac: "aa bb cc", notation: NotationSpace},
+		{mac: "aa.bb.cc.dd.ee.ff", notation: NotationDot},
+		{mac: "aa_bb_cc_dd_ee_ff", notation: NotationOther},
+		{mac: "aa/bb/cc", notation: NotationOther},
+		{mac: "aabbcc", notation: NotationBare},
+		// Too many octets.
+		{mac: "aa:bb:cc:dd:ee:ff:00", invalid: true},
+		{mac: "aa bb cc dd ee ff 00 11", invalid: true},
+		{mac: "aabbccddeeff0011", invalid: true},
+		// Mixed or missing separators.
+		{mac: "aa:bb-cc", invalid: true},
+		{mac: "aa bb", invalid: true},
+		{mac: "aa:bb:c", invalid: true},
+		// The last digit would be dropped.
+		{mac: "aabbccd", invalid: true},
+		{mac: "aabbccddeef", invalid: true},
+	}
+	for _, test := range tests {
+		hw, n, err := ParseMacFormat(test.mac)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("ParseMacFormat(%q) = %s, want an error", test.mac, hw)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMacFormat(%q): %v", test.mac, err)
+			continue
+		}
+		if *hw != want || n != test.notation {
+			t.Errorf("ParseMacFormat(%q) = %s, %s, want %s, %s", test.mac, hw, n, want, test.notation)
+		}
+	}
+	if _, err := ParsePrefix("aa:bb:cc:dd:ee:ff:00/28"); err == nil {
+		t.Error("ParsePrefix accepted 7 octets")
+	}
+}
+
+func FuzzParseMac(f *testing.F) {
+	for _, s := range []string{"00:22:72", "00-22-72-01-02-03", "0022.7201.0203", "002272010203", "00 22 72", "00_22_72", "0g:00:00", "", "00:22:72:01:02:03:04"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		hw, err := ParseMac(s)
+		if err != nil {
+			return
+		}
+		// Everything ParseMac accepts is accepted by ParseMacFormat.
+		fhw, n, err := ParseMacFormat(s)
+		if err != nil {
+			t.Fatalf("ParseMacFormat(%q) failed after ParseMac returned %s: %v", s, hw, err)
+		}
+		if *fhw != *hw {
+			t.Fatalf("ParseMacFormat(%q) = %s, ParseMac = %s", s, fhw, hw)
+		}
+		// The address in the detected notation is parsed to the same address.
+		again, _, err := ParseMacFormat(n.Format(*hw))
+		if err != nil || *again != *hw {
+			t.Fatalf("%s written as %q parsed to %v, %v", hw, n.Format(*hw), again, err)
+		}
+	})
+}
+
+func TestParseRADIUSStationID(t *testing.T) {
+	want := HardwareAddr{0x00, 0x22, 0x72}
+	for _, id := range []string{
+		"00-22-72-A1-B2-C3",
+		"00-22-72-A1-B2-C3:Corp WiFi",
+		"002272a1b2c3",
+		"0022.72A1.B2C3",
+		"00:22:72:a1:b2:c3",
+		`"00-22-72-A1-B2-C3:eduroam"`,
+		" 00-22-72-A1-B2-C3 ",
+	} {
+		hw, err := ParseRADIUSStationID(id)
+		if err != nil {
+			t.Errorf("ParseRADIUSStationID(%q): %v", id, err)
+			continue
+		}
+		if *hw != want {
+			t.Errorf("ParseRADIUSStationID(%q) = %s, want %s", id, hw, want)
+		}
+	}
+	for _, id := range []string{"", "00-22-72", "00-22-72-A1-B2", "00-22-72-A1-B2-C3-D4", "Corp WiFi", "00-22-72:A1-B2-C3"} {
+		if hw, err := ParseRADIUSStationID(id); err == nil {
+			t.Errorf("ParseRADIUSStationID(%q) = %s, want an error", id, hw)
+		}
+	}
+}
+
+func TestParseMacPrefix(t *testing.T) {
+	want := HardwareAddr{0xaa, 0xbb, 0xcc}
+	tests := []struct {
+		s       string
+		rest    string
+		invalid bool
+	}{
+		{s: "aa:bb:cc:dd:ee:ff", rest: ""},
+		{s: "  AA-BB-CC-DD-EE-FF eth0", rest: " eth0"},
+		{s: "aabb.ccdd.eeff @ Gi1/0/1", rest: " @ Gi1/0/1"},
+		{s: "aabbccddeeff,vlan 10", rest: ",vlan 10"},
+		{s: "aabbcc rest", rest: " rest"},
+		{s: "aabbccdd rest", rest: " rest"},
+		{s: "aa bb cc dd ee ff\tport 1", rest: "\tport 1"},
+		{s: "aa bb cc rest", rest: " rest"},
+		{s: "aa.bb.cc.dd.ee.ff;", rest: ";"},
+		{s: "aa_bb_cc_dd_ee_ff port", rest: " port"},
+		{s: "aa/bb/cc", rest: ""},
+		// Odd or too many digits without separators.
+		{s: "aabbccd", invalid: true},
+		{s: "aabbccd rest", invalid: true},
+		{s: "aabbccddeeff00", invalid: true},
+		// Followed by a separator, or too short.
+		{s: "aa:bb:cc:", invalid: true},
+		{s: "aa_bb_cc_", invalid: true},
+		{s: "aa:bb:cc:dd:ee:ff:00", invalid: true},
+		{s: "aa:bb rest", invalid: true},
+		{s: "aabb rest", invalid: true},
+		{s: "", invalid: true},
+		{s: "host aa:bb:cc", invalid: true},
+	}
+	for _, test := range tests {
+		hw, rest, err := ParseMacPrefix(test.s)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("ParseMacPrefix(%q) = %s, %q, want an error", test.s, hw, rest)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMacPrefix(%q): %v", test.s, err)
+			continue
+		}
+		if *hw != want || rest != test.rest {
+			t.Errorf("ParseMacPrefix(%q) = %s, %q, want %s, %q", test.s, hw, rest, want, test.rest)
+		}
+	}
+}
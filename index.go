@@ -0,0 +1,211 @@
+package oui
+
+import (
+	"io"
+	"sync"
+)
+
+// Index is storage for the entries of a database opened with OpenWithIndex.
+// It allows entries to be kept outside of memory, for instance in a key/value store.
+// Entries are stored for their assignment, see Entry.Assignment, so MA-M
+// and MA-S entries within the same OUI are stored separately.
+//
+// The database serializes calls to the index, so Put and Delete are never
+// called at the same time as any other method. Get and Walk may be called
+// concurrently from several goroutines.
+// The database only keeps the assignments stored in memory, to find the
+// longest assignment of an address, so implementations must store all
+// fields of the entries they are given.
+type Index interface {
+	// Get returns the entry stored for the assignment.
+	// If there is none, false and a nil error must be returned.
+	Get(assignment Prefix) (Entry, bool, error)
+
+	// Put stores the entry for the assignment, replacing any existing entry.
+	Put(assignment Prefix, e Entry) error
+
+	// Delete removes the entry stored for the assignment.
+	// Deleting an assignment that isn't stored must not return an error.
+	Delete(assignment Prefix) error
+
+	// Walk calls fn for all stored entries until it returns false.
+	// The order is undefined.
+	Walk(fn func(Entry) bool) error
+}
+
+// A store keeping entries in an Index.
+// The assignments stored and the shadowed entries are kept in memory.
+type indexStore struct {
+	idx  Index
+	keys prefixTrie[struct{}]
+	dups duplicates
+	// Held for reading while the index is walked, since walks are not
+	// serialized by the database, and Put and Delete must not be called
+	// at the same time.
+	mu sync.RWMutex
+}
+
+// OpenWithIndex will read an oui.txt file and store the entries in idx,
+// and return a database that looks up entries in idx.
+// If idx is nil, entries are kept in memory like Open.
+// Existing entries in idx are kept, unless they are replaced by the file.
+// The options are applied like Open, but the strings of WithPackedStrings
+// are only shared if the index keeps the entries it is given.
+// Entries shadowed when WithKeepDuplicates is given are kept in memory.
+// The file is read into memory before it is stored in idx, so if it
+// cannot be read, the error is returned and idx is not modified.
+//
+// The database can be updated using the Update/UpdateFile/UpdateHttp functions,
+// but the update is read into memory before it is stored in the index.
+// Errors from the index are returned by the Update functions and ApplyDelta.
+// If an update fails to be stored, the index may be partially updated,
+// while a delta that fails is undone.
+// Errors from UpdateEntry, DeleteEntry and Reset cannot be returned,
+// so the index must handle them.
+//
+// The database cannot be updated while it is iterated, so the function given
+// to Iterate must not modify it.
+func OpenWithIndex(r io.Reader, idx Index, opts ...Option) (DynamicDB, error) {
+	if idx == nil {
+		return Open(r, opts...)
+	}
+	o := newOptions(opts)
+	dst := o.newDB()
+	t, dups, err := load(r, dst, o)
+	if err != nil {
+		return nil, err
+	}
+	st := &indexStore{idx: idx, keys: newPrefixTrie[struct{}](o.capacity), dups: dups}
+	if werr := idx.Walk(func(e Entry) bool {
+		st.keys.set(e.Assignment(), struct{}{})
+		return true
+	}); werr != nil {
+		return nil, werr
+	}
+	for k, e := range dst {
+		if perr := idx.Put(k, e); perr != nil {
+			return nil, perr
+		}
+		st.keys.set(k, struct{}{})
+	}
+	db := newDatabase(st, o)
+	db.generatedAt(t)
+	return updateableDB{db}, nil
+}
+
+func (s *indexStore) get(p Prefix) (Entry, bool, error) {
+	s.mu.RLock()
+	k, _, ok := s.keys.longest(p)
+	s.mu.RUnlock()
+	if !ok {
+		return Entry{}, false, nil
+	}
+	return s.idx.Get(k)
+}
+
+func (s *indexStore) hasLonger(p Prefix) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys.hasLonger(p)
+}
+
+func (s *indexStore) longer(p Prefix, fn func(Entry) bool) error {
+	var keys []Prefix
+	s.mu.RLock()
+	s.keys.longer(p, func(k Prefix, _ struct{}) bool {
+		keys = append(keys, k)
+		return true
+	})
+	s.mu.RUnlock()
+	for _, k := range keys {
+		e, ok, err := s.idx.Get(k)
+		if err != nil {
+			return err
+		}
+		if ok && !fn(e) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *indexStore) shadowed(k Prefix) []Entry {
+	return s.dups[k]
+}
+
+func (s *indexStore) walk(fn func(Entry) bool) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.idx.Walk(fn)
+}
+
+func (s *indexStore) len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys.len()
+}
+
+// The index cannot be copied, so walks block updates instead.
+func (s *indexStore) clone() store {
+	return s
+}
+
+// Only the assignments and the shadowed entries are kept in memory.
+func (s *indexStore) memBytes() int64 {
+	return trieBytes(&s.keys) + dupsBytes(s.dups)
+}
+
+func (s *indexStore) blocking() bool {
+	return true
+}
+
+func (s *indexStore) set(e Entry) error {
+	k := e.Assignment()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.dups, k)
+	if err := s.idx.Put(k, e); err != nil {
+		return err
+	}
+	s.keys.set(k, struct{}{})
+	return nil
+}
+
+func (s *indexStore) del(k Prefix) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.dups, k)
+	if err := s.idx.Delete(k); err != nil {
+		return err
+	}
+	s.keys.del(k)
+	return nil
+}
+
+// Replace the content of the index.
+// Entries that are not in the new content are deleted.
+func (s *indexStore) replace(db ouiDB, dups duplicates) (writableStore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var remove []Prefix
+	s.keys.walk(func(k Prefix, _ struct{}) bool {
+		if _, ok := db[k]; !ok {
+			remove = append(remove, k)
+		}
+		return true
+	})
+	for _, k := range remove {
+		if err := s.idx.Delete(k); err != nil {
+			return nil, err
+		}
+		s.keys.del(k)
+	}
+	for k, e := range db {
+		if err := s.idx.Put(k, e); err != nil {
+			return nil, err
+		}
+		s.keys.set(k, struct{}{})
+	}
+	s.dups = dups
+	return s, nil
+}
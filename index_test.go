@@ -0,0 +1,131 @@
+package oui
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+var errIndexFull = errors.New("index full")
+
+// An index keeping entries in memory, and failing
+// when more than limit entries would be stored.
+type limitIndex struct {
+	m     map[Prefix]Entry
+	limit int
+}
+
+func (l *limitIndex) Get(prefix Prefix) (Entry, bool, error) {
+	e, ok := l.m[prefix]
+	return e, ok, nil
+}
+
+func (l *limitIndex) Put(prefix Prefix, e Entry) error {
+	if _, ok := l.m[prefix]; !ok && len(l.m) >= l.limit {
+		return errIndexFull
+	}
+	l.m[prefix] = e
+	return nil
+}
+
+func (l *limitIndex) Delete(prefix Prefix) error {
+	delete(l.m, prefix)
+	return nil
+}
+
+func (l *limitIndex) Walk(fn func(Entry) bool) error {
+	for _, e := range l.m {
+		if !fn(e) {
+			break
+		}
+	}
+	return nil
+}
+
+func openIndexed(t *testing.T, idx Index, opts ...Option) DynamicDB {
+	t.Helper()
+	f, err := os.Open("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	db, err := OpenWithIndex(f, idx, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestOpenWithIndexOptions(t *testing.T) {
+	idx := &limitIndex{m: make(map[Prefix]Entry), limit: 100}
+	db := openIndexed(t, idx, WithSourceLines(), WithSource("test"))
+	if db.Len() != len(idx.m) || db.Len() == 0 {
+		t.Fatalf("Len = %d, index has %d entries", db.Len(), len(idx.m))
+	}
+	e, err := db.Query("00:60:92")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Source != "test" {
+		t.Errorf("Source = %q, want test", e.Source)
+	}
+	if !strings.Contains(e.SourceLine(), "MICRO/SYS") {
+		t.Errorf("SourceLine = %q, want the record", e.SourceLine())
+	}
+}
+
+func TestIndexUpdateError(t *testing.T) {
+	idx := &limitIndex{m: make(map[Prefix]Entry), limit: 100}
+	db := openIndexed(t, idx)
+	idx.limit = len(idx.m)
+	b, err := os.ReadFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := string(b) + "\r\n02-00-01   (hex)\t\tAdded\r\n"
+	if err := Update(db, strings.NewReader(update)); !errors.Is(err, errIndexFull) {
+		t.Errorf("Update error = %v, want %v", err, errIndexFull)
+	}
+}
+
+func TestIndexApplyDeltaUndo(t *testing.T) {
+	idx := &limitIndex{m: make(map[Prefix]Entry), limit: 100}
+	db := openIndexed(t, idx)
+	n := db.Len()
+	idx.limit = n
+	// The deletion frees room for the first addition, but not the second.
+	delta := `- 00:60:92
++ {"prefix":"02:00:01","manufacturer":"First"}
++ {"prefix":"02:00:02","manufacturer":"Second"}
+`
+	if err := db.ApplyDelta(strings.NewReader(delta)); !errors.Is(err, errIndexFull) {
+		t.Fatalf("ApplyDelta error = %v, want %v", err, errIndexFull)
+	}
+	if db.Len() != n {
+		t.Errorf("Len after failed delta = %d, want %d", db.Len(), n)
+	}
+	if _, err := db.Query("00:60:92"); err != nil {
+		t.Errorf("deleted entry not restored: %v", err)
+	}
+	if _, err := db.Query("02:00:01"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("added entry not removed, got error %v", err)
+	}
+}
+
+func TestOpenWithIndexParseError(t *testing.T) {
+	b, err := os.ReadFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := Entry{Prefix: HardwareAddr{0x02, 0x00, 0x01}, Manufacturer: "Existing"}
+	idx := &limitIndex{m: map[Prefix]Entry{existing.Assignment(): existing}, limit: 100}
+	// The invalid range fails after the records of the file are read.
+	invalid := string(b) + "\r\n70-B3-D5   (hex)\t\tBroken\r\n123456-1234FF     (base 16)\t\tBroken\r\n\r\n"
+	if _, err := OpenWithIndex(strings.NewReader(invalid), idx); err == nil {
+		t.Fatal("no error opening an invalid file")
+	}
+	if len(idx.m) != 1 || idx.m[existing.Assignment()].Manufacturer != "Existing" {
+		t.Errorf("index has %d entries after a failed open, want only the existing entry", len(idx.m))
+	}
+}
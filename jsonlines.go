@@ -0,0 +1,105 @@
+package oui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidRecord will be returned when a record in the input
+// cannot be decoded. Line is the line number of the record, starting at 1.
+type ErrInvalidRecord struct {
+	Line   int
+	Reason string
+}
+
+// Error returns a string representation of the error.
+func (e ErrInvalidRecord) Error() string {
+	return fmt.Sprintf("invalid record on line %d: %s", e.Line, e.Reason)
+}
+
+// OpenJSONLines will read newline delimited JSON entries and return a database with the content.
+// Each line must contain a JSON object as written by WriteJSONLines, with at least
+// a prefix and a manufacturer. Empty lines are ignored.
+// If a line cannot be decoded, an ErrInvalidRecord with the line number is returned,
+// unless WithLenientParsing is given.
+// The entries are loaded like the entries of a oui.txt file, so options like
+// WithSource, WithMaxEntries and WithKeepDuplicates are applied.
+// You can update the returned database using the Update/UpdateFile/UpdateHttp functions,
+// which will read the new content as JSON lines too.
+func OpenJSONLines(r io.Reader, opts ...Option) (DynamicDB, error) {
+	o := newOptions(opts)
+	o.format = FormatJSONLines
+	dst := o.newDB()
+	t, dups, err := load(r, dst, o)
+	if err != nil {
+		return nil, err
+	}
+	db := newDynamic(dst, dups, o)
+	db.generatedAt(t)
+	return db, nil
+}
+
+// Read the records of a JSON lines file.
+// Records that cannot be decoded are skipped if lenient parsing is enabled in o.
+func scanJSONLines(r io.Reader, o options, fn recordFunc) error {
+	scanner := bufio.NewScanner(r)
+	// Allow entries with long address blocks.
+	scanner.Buffer(nil, 1<<20)
+	if o.report != nil {
+		*o.report = ParseReport{}
+	}
+	line := 0
+	var off int64
+	for scanner.Scan() {
+		line++
+		b := scanner.Bytes()
+		start := off
+		off += int64(len(b)) + 1
+		if len(b) == 0 {
+			continue
+		}
+		e, err := decodeJSONEntry(b)
+		if err != nil {
+			invalid := ErrInvalidRecord{Line: line, Reason: err.Error()}
+			if o.warn(o.report, invalid) {
+				continue
+			}
+			return invalid
+		}
+		if o.report != nil {
+			o.report.Records++
+		}
+		if err := fn(*e, start, int64(len(b))); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Decode and validate a single JSON entry.
+func decodeJSONEntry(b []byte) (*Entry, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["prefix"]; !ok {
+		return nil, fmt.Errorf("no prefix")
+	}
+	var e Entry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, err
+	}
+	if e.Manufacturer == "" {
+		return nil, fmt.Errorf("no manufacturer")
+	}
+	return &e, nil
+}
+
+// WriteJSONLines will write all entries in the database as newline delimited JSON,
+// sorted by prefix.
+// The output can be read with OpenJSONLines.
+func WriteJSONLines(db OuiDB, w io.Writer) error {
+	return ExportFiltered(db, w, nil, FormatJSONLines)
+}
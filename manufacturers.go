@@ -0,0 +1,87 @@
+package oui
+
+import (
+	"errors"
+	"sort"
+)
+
+// ManufacturerCounts returns the number of prefixes held by each manufacturer.
+// If the database cannot be read completely, the entries read so far are counted.
+func ManufacturerCounts(db OuiDB) map[string]int {
+	res := make(map[string]int)
+	db.walk(func(e Entry) bool {
+		res[e.Manufacturer]++
+		return true
+	})
+	return res
+}
+
+// Manufacturers returns the sorted, distinct manufacturer names in the database.
+func Manufacturers(db OuiDB) []string {
+	counts := ManufacturerCounts(db)
+	res := make([]string, 0, len(counts))
+	for m := range counts {
+		res = append(res, m)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// SameVendor will look up both hardware addresses and return true
+// if they are assigned to the same manufacturer.
+// Manufacturers are compared case insensitively, ignoring differences in whitespace.
+// If either lookup fails, the error is returned.
+func SameVendor(db ReadOnlyDB, a, b HardwareAddr) (bool, error) {
+	ea, err := db.LookUp(a)
+	if err != nil {
+		return false, err
+	}
+	eb, err := db.LookUp(b)
+	if err != nil {
+		return false, err
+	}
+	return ea.NormalizedManufacturer() == eb.NormalizedManufacturer(), nil
+}
+
+// VendorPrefixCounts returns the number of prefixes held by each manufacturer,
+// keyed by Entry.NormalizedManufacturer, so names differing only in case
+// or whitespace are counted together.
+func VendorPrefixCounts(db OuiDB) map[string]int {
+	res := make(map[string]int)
+	db.walk(func(e Entry) bool {
+		res[e.NormalizedManufacturer()]++
+		return true
+	})
+	return res
+}
+
+// Keys used by VendorHistogram for addresses without a manufacturer.
+const (
+	// VendorNotFound counts addresses that couldn't be looked up.
+	VendorNotFound = "(not found)"
+	// VendorLocallyAdministered counts locally administered addresses,
+	// which are never assigned to a manufacturer.
+	VendorLocallyAdministered = "(locally administered)"
+)
+
+// VendorHistogram will look up all addresses and return the number of addresses
+// assigned to each manufacturer. Addresses are counted for every time they appear.
+// Locally administered unicast addresses without an entry in the database are
+// counted as VendorLocallyAdministered, and other addresses that cannot be
+// looked up as VendorNotFound.
+func VendorHistogram(db ReadOnlyDB, addrs []HardwareAddr) map[string]int {
+	res := make(map[string]int)
+	for _, hw := range addrs {
+		e, err := db.LookUp(hw)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) && hw.Local() && !hw.Multicast() {
+				res[VendorLocallyAdministered]++
+			} else {
+				res[VendorNotFound]++
+			}
+			continue
+		}
+		res[e.Manufacturer]++
+	}
+	return res
+}
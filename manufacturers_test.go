@@ -0,0 +1,53 @@
+package oui
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVendorHistogramLocal(t *testing.T) {
+	db, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A locally administered prefix registered in the database.
+	registered := HardwareAddr{0x0a, 0x00, 0x27}
+	db.UpdateEntry(registered, Entry{Prefix: registered, Manufacturer: "Local Org"})
+	addrs := []HardwareAddr{
+		{0x00, 0x22, 0x72},
+		registered,
+		{0x0a, 0x00, 0x28},
+		{0x00, 0xab, 0xcd},
+	}
+	want := map[string]int{
+		"American Micro-Fuel Device Corp.": 1,
+		"Local Org":                        1,
+		VendorLocallyAdministered:          1,
+		VendorNotFound:                     1,
+	}
+	if got := VendorHistogram(db, addrs); !reflect.DeepEqual(got, want) {
+		t.Errorf("VendorHistogram = %v, want %v", got, want)
+	}
+}
+
+func TestAnnotateCaptureLocal(t *testing.T) {
+	db, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered := HardwareAddr{0x0a, 0x00, 0x27}
+	db.UpdateEntry(registered, Entry{Prefix: registered, Manufacturer: "Local Org"})
+	var buf bytes.Buffer
+	in := "mac\n0a:00:27:01:02:03\n0a:00:28:01:02:03\n00:ab:cd:01:02:03\n"
+	if err := AnnotateCapture(strings.NewReader(in), db, &buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, vendor := range []string{"Local Org", VendorLocallyAdministered, VendorNotFound} {
+		if !strings.Contains(lines[i+1], ","+vendor+",") {
+			t.Errorf("annotated %q, want the vendor %q", lines[i+1], vendor)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package oui
+
+import "unsafe"
+
+// The size of a string header.
+const stringHeader = int64(unsafe.Sizeof(""))
+
+// Estimate the memory used by a map with n entries,
+// where a key and value together use slot bytes.
+// Maps keep a byte of hash per slot, and grow before they are full,
+// so a fifth of the slots are assumed to be empty on average.
+func mapBytes(n int, slot uintptr) int64 {
+	return int64(n) * (int64(slot) + 1) * 5 / 4
+}
+
+// Estimate the memory used by the strings and slices of an entry,
+// not counting the entry itself.
+func entryBytes(e *Entry) int64 {
+	n := int64(len(e.Manufacturer) + len(e.Source) + len(e.RegistrationID) + len(e.sourceLine) + len(e.address))
+	// The country is usually the last address line, and shares memory with it.
+	if (len(e.Address) == 0 || e.Country != e.Address[len(e.Address)-1]) && (e.address == "" || e.Country != e.lastAddressLine()) {
+		n += int64(len(e.Country))
+	}
+	for _, a := range e.Address {
+		n += stringHeader + int64(len(a))
+	}
+	for _, t := range e.Tags {
+		n += stringHeader + int64(len(t))
+	}
+	return n
+}
+
+// Estimate the memory used by entries held in memory, including shadowed entries.
+func entriesBytes(db *prefixTrie[Entry], dups duplicates) int64 {
+	n := trieBytes(db)
+	db.walk(func(_ Prefix, e Entry) bool {
+		n += entryBytes(&e)
+		return true
+	})
+	return n + dupsBytes(dups)
+}
+
+// Estimate the memory used by shadowed entries.
+func dupsBytes(dups duplicates) int64 {
+	n := mapBytes(len(dups), unsafe.Sizeof(Prefix{})+unsafe.Sizeof([]Entry{}))
+	for _, d := range dups {
+		n += int64(len(d)) * int64(unsafe.Sizeof(Entry{}))
+		for i := range d {
+			n += entryBytes(&d[i])
+		}
+	}
+	return n
+}
+
+// Estimate the memory used by the nodes of a trie, including the values,
+// but not the memory the values refer to.
+func trieBytes[T any](t *prefixTrie[T]) int64 {
+	return mapBytes(len(t.ouis), unsafe.Sizeof(HardwareAddr{})+unsafe.Sizeof(t.short)) +
+		int64(t.nodes())*int64(unsafe.Sizeof(trieNode[T]{}))
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package oui
+
+import "os"
+
+// Memory mapping is only done on unix systems, so the file is read with ReadAt.
+func mapFile(f *os.File, size int64) readerAtCloser {
+	return f
+}
@@ -0,0 +1,65 @@
+//go:build unix
+
+package oui
+
+import (
+	"io"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// A file mapped into memory read-only.
+// Reads after the file is closed return os.ErrClosed, instead of
+// touching memory that is no longer mapped.
+type mappedFile struct {
+	mu   sync.RWMutex
+	b    []byte
+	file *os.File
+}
+
+// Map the file into memory, so records are read without a system call per lookup.
+// The pages are shared with the page cache, and are only resident while used.
+// If the file cannot be mapped, it is read with ReadAt.
+// Closing the returned reader closes the file.
+func mapFile(f *os.File, size int64) readerAtCloser {
+	if size <= 0 || int64(int(size)) != size {
+		return f
+	}
+	b, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return f
+	}
+	return &mappedFile{b: b, file: f}
+}
+
+func (m *mappedFile) ReadAt(p []byte, off int64) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.b == nil {
+		return 0, os.ErrClosed
+	}
+	if off < 0 || off >= int64(len(m.b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.b[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close will unmap the file and close it.
+func (m *mappedFile) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.b == nil {
+		return os.ErrClosed
+	}
+	err := syscall.Munmap(m.b)
+	m.b = nil
+	if cerr := m.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
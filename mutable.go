@@ -0,0 +1,219 @@
+package oui
+
+import (
+	"os"
+	"unsafe"
+)
+
+// A store with a read-only base backed by a file,
+// and an in-memory overlay containing all changes.
+// The overlay is consulted before the base.
+type overlayStore struct {
+	base    *fileStore
+	overlay memStore
+	// Entries deleted from the base.
+	deleted map[Prefix]struct{}
+	// The number of entries in the base hidden by the overlay or deleted.
+	hiddenBase int
+}
+
+// OpenStaticMutable will index the oui.txt file with the given name and return
+// a database that can be updated.
+//
+// The file is indexed like OpenStaticReaderAt, so only the location of each record
+// is kept in memory, and entries are read from the file when they are looked up.
+// The file is never written to. It is kept open while the database is used,
+// and must not be modified. On unix systems the file is mapped into memory
+// read-only, so the pages are shared with the page cache and other processes
+// mapping the file, and only the index and the updates are held by the database.
+// The returned database implements io.Closer, and should be closed to close the file
+// when it is no longer used, also after it has been updated.
+// Lookups after the database is closed are invalid.
+//
+// Updates are kept in memory, and are consulted before the file.
+// Replacing the content with Update/UpdateFile/UpdateHttp will stop
+// using the file, and keep the new content in memory.
+// The options given are used when the database is updated.
+func OpenStaticMutable(name string, opts ...Option) (DynamicDB, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	st, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r := mapFile(file, st.Size())
+	o := newOptions(opts)
+	base, t, err := indexFile(r, st.Size(), o.capacity)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	// The overlay only holds updates, so the capacity is only used if the content is replaced.
+	db := newDatabase(&overlayStore{
+		base:    base,
+		overlay: memStore{db: newPrefixTrie[Entry](0), capacity: o.capacity},
+		deleted: make(map[Prefix]struct{}),
+	}, o)
+	db.generatedAt(t)
+	db.file = r
+	return updateableDB{db}, nil
+}
+
+// Returns true if the entry of the file for k is hidden by an update or deletion.
+func (s *overlayStore) hidden(k Prefix) bool {
+	if _, ok := s.overlay.db.get(k); ok {
+		return true
+	}
+	_, ok := s.deleted[k]
+	return ok
+}
+
+// The longest assignment is taken from the overlay,
+// unless the file has a longer one that isn't hidden.
+func (s *overlayStore) get(p Prefix) (Entry, bool, error) {
+	top, e, found := s.overlay.db.longest(p)
+	for q := p; q.Bits > 0; q = q.truncate(q.Bits - 1) {
+		k, sp, inBase := s.base.index.longest(q)
+		if !inBase || found && k.Bits <= top.Bits {
+			break
+		}
+		if s.hidden(k) {
+			q = k
+			continue
+		}
+		be, err := s.base.read(sp)
+		if err != nil {
+			return Entry{}, false, err
+		}
+		return *be, true, nil
+	}
+	return e, found, nil
+}
+
+func (s *overlayStore) hasLonger(p Prefix) bool {
+	if s.overlay.hasLonger(p) {
+		return true
+	}
+	found := false
+	s.base.index.longer(p, func(k Prefix, _ span) bool {
+		found = !s.hidden(k)
+		return !found
+	})
+	return found
+}
+
+func (s *overlayStore) longer(p Prefix, fn func(Entry) bool) error {
+	done := false
+	s.overlay.longer(p, func(e Entry) bool {
+		done = !fn(e)
+		return !done
+	})
+	if done {
+		return nil
+	}
+	var spans []span
+	s.base.index.longer(p, func(k Prefix, sp span) bool {
+		if !s.hidden(k) {
+			spans = append(spans, sp)
+		}
+		return true
+	})
+	for _, sp := range spans {
+		e, err := s.base.read(sp)
+		if err != nil {
+			return err
+		}
+		if !fn(*e) {
+			break
+		}
+	}
+	return nil
+}
+
+// Only entries in the overlay can have shadowed entries.
+func (s *overlayStore) shadowed(k Prefix) []Entry {
+	return s.overlay.shadowed(k)
+}
+
+// The entries in the overlay are merged with the entries in the file
+// that haven't been updated or deleted, so they are walked in order.
+func (s *overlayStore) walk(fn func(Entry) bool) error {
+	var overlay []Entry
+	s.overlay.walk(func(e Entry) bool {
+		overlay = append(overlay, e)
+		return true
+	})
+	done := false
+	err := s.base.walkKeys(func(k Prefix, e Entry) bool {
+		if s.hidden(k) {
+			return true
+		}
+		for len(overlay) > 0 && overlay[0].Assignment().less(k) {
+			if done = !fn(overlay[0]); done {
+				return false
+			}
+			overlay = overlay[1:]
+		}
+		done = !fn(e)
+		return !done
+	})
+	if err != nil || done {
+		return err
+	}
+	for _, e := range overlay {
+		if !fn(e) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *overlayStore) len() int {
+	return s.overlay.len() + s.base.len() - s.hiddenBase
+}
+
+// Count the entry of the file for k as hidden, if it is about to be hidden.
+func (s *overlayStore) hide(k Prefix) {
+	if _, ok := s.base.index.get(k); ok && !s.hidden(k) {
+		s.hiddenBase++
+	}
+}
+
+// The overlay is copied, and the file is shared, since it is never modified.
+func (s *overlayStore) clone() store {
+	deleted := make(map[Prefix]struct{}, len(s.deleted))
+	for k := range s.deleted {
+		deleted[k] = struct{}{}
+	}
+	return &overlayStore{base: s.base, overlay: *s.overlay.clone().(*memStore), deleted: deleted, hiddenBase: s.hiddenBase}
+}
+
+func (s *overlayStore) memBytes() int64 {
+	return s.overlay.memBytes() + mapBytes(len(s.deleted), unsafe.Sizeof(Prefix{})) + s.base.memBytes()
+}
+
+func (s *overlayStore) blocking() bool {
+	return true
+}
+
+// Set an entry in the overlay.
+func (s *overlayStore) set(e Entry) error {
+	s.hide(e.Assignment())
+	delete(s.deleted, e.Assignment())
+	return s.overlay.set(e)
+}
+
+// Delete an entry from the overlay, and hide it in the file.
+func (s *overlayStore) del(k Prefix) error {
+	s.hide(k)
+	s.deleted[k] = struct{}{}
+	return s.overlay.del(k)
+}
+
+// The new content is kept in memory, so the file is no longer used.
+func (s *overlayStore) replace(db ouiDB, dups duplicates) (writableStore, error) {
+	return newMemStore(db, dups, s.overlay.capacity), nil
+}
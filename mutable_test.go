@@ -0,0 +1,79 @@
+package oui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMutableIterateWhileUpdating(t *testing.T) {
+	db, err := OpenStaticMutable("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.(interface{ Close() error }).Close()
+	n := db.Len()
+	added := HardwareAddr{0x02, 0x00, 0x01}
+	done := make(chan int)
+	go func() {
+		seen := 0
+		db.Iterate(func(e *Entry) bool {
+			// Holding a lock while calling fn would deadlock here.
+			db.UpdateEntry(added, Entry{Prefix: added, Manufacturer: "Added"})
+			db.DeleteEntry(e.Prefix)
+			seen++
+			return true
+		})
+		done <- seen
+	}()
+	select {
+	case seen := <-done:
+		if seen != n {
+			t.Errorf("iterated %d entries, want the %d in the snapshot", seen, n)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Iterate blocked updates made by fn")
+	}
+	if got := db.Len(); got != 1 {
+		t.Errorf("Len after deleting all entries = %d, want 1", got)
+	}
+	if _, err := db.LookUp(added); err != nil {
+		t.Errorf("LookUp(%s): %v", added, err)
+	}
+}
+
+func TestMutableLen(t *testing.T) {
+	db, err := OpenStaticMutable("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.(interface{ Close() error }).Close()
+	inFile := HardwareAddr{0x00, 0x22, 0x72}
+	added := HardwareAddr{0x02, 0x00, 0x01}
+	ops := []struct {
+		name string
+		fn   func()
+	}{
+		{"update entry in file", func() { db.UpdateEntry(inFile, Entry{Prefix: inFile, Manufacturer: "Updated"}) }},
+		{"update it again", func() { db.UpdateEntry(inFile, Entry{Prefix: inFile, Manufacturer: "Again"}) }},
+		{"delete it", func() { db.DeleteEntry(inFile) }},
+		{"delete it again", func() { db.DeleteEntry(inFile) }},
+		{"add it back", func() { db.UpdateEntry(inFile, Entry{Prefix: inFile, Manufacturer: "Back"}) }},
+		{"add new entry", func() { db.UpdateEntry(added, Entry{Prefix: added, Manufacturer: "Added"}) }},
+		{"delete new entry", func() { db.DeleteEntry(added) }},
+		{"delete missing entry", func() { db.DeleteEntry(HardwareAddr{0x02, 0x00, 0x02}) }},
+		{"delete other entry in file", func() { db.DeleteEntry(HardwareAddr{0x00, 0x60, 0x92}) }},
+	}
+	for _, op := range ops {
+		op.fn()
+		n := 0
+		if err := db.Iterate(func(*Entry) bool {
+			n++
+			return true
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if db.Len() != n {
+			t.Errorf("%s: Len = %d, Iterate gave %d entries", op.name, db.Len(), n)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package oui
+
+import "math/bits"
+
+// Nearest will return the entry with the prefix closest to the hardware address,
+// and the number of trailing bits of the 24 bit prefix that differ.
+// If the address is in the database, the entry is returned with a distance of 0.
+// Ties are broken by numeric distance, and then by the lowest assignment,
+// so the 24 bit entry of an OUI is preferred over MA-M and MA-S entries within it.
+// Adjacent blocks are often assigned to the same manufacturer, but this is only
+// a heuristic, and the entry should not be taken as the owner of the address.
+// If the database is empty, ErrNotInitialized is returned.
+func Nearest(db OuiDB, hw HardwareAddr) (*Entry, int, error) {
+	target := prefixValue(hw)
+	var best *Entry
+	bestBits, bestDist := 0, uint32(0)
+	err := db.walk(func(e Entry) bool {
+		v := prefixValue(e.Prefix)
+		n := bits.Len32(v ^ target)
+		dist := v - target
+		if v < target {
+			dist = target - v
+		}
+		if best == nil || n < bestBits || (n == bestBits && (dist < bestDist ||
+			(dist == bestDist && e.Assignment().less(best.Assignment())))) {
+			e := e
+			best, bestBits, bestDist = &e, n, dist
+		}
+		return n > 0 || best.bits() > 24
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if best == nil {
+		return nil, 0, ErrNotInitialized
+	}
+	setConfidence(best, false)
+	if bestBits > 0 {
+		best.Confidence = ConfidenceVeryLow
+	}
+	return best, bestBits, nil
+}
+
+// The prefix as a 24 bit number.
+func prefixValue(hw HardwareAddr) uint32 {
+	return uint32(hw[0])<<16 | uint32(hw[1])<<8 | uint32(hw[2])
+}
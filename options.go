@@ -0,0 +1,380 @@
+package oui
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Option can be given when opening a database to change how it is loaded.
+type Option func(*options)
+
+type options struct {
+	packStrings    bool
+	joinAddresses  bool
+	progress       func(parsed int)
+	progressEvery  int
+	keepDuplicates bool
+	report         *ParseReport
+	maxEntries     int
+	registries     map[Registry]struct{}
+	source         string
+	capacity       int
+	sourceLines    bool
+	maxAddress     int
+	lenient        bool
+	clock          Clock
+	// The format read when the database was opened, so updates read the same.
+	format Format
+}
+
+// Entries that share an assignment with a later entry, in the order they were read.
+type duplicates map[Prefix][]Entry
+
+// Collect the given options.
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithPackedStrings will store the text of all entries in a single allocation
+// once the database has been loaded.
+// This reduces the number of objects the garbage collector must track
+// from several per entry to a few for the entire database.
+// The address lines of all entries are sliced from a single slice,
+// so there is no allocation per entry for the address either.
+// Use Entry.AddressString to get the address as a single string.
+// Entries added with UpdateEntry are stored as given.
+func WithPackedStrings() Option {
+	return func(o *options) {
+		o.packStrings = true
+	}
+}
+
+// WithJoinedAddresses will store the address of each entry as a single string,
+// with the lines separated by '\n', instead of a slice with a string per line.
+// Entry.Address is nil for the entries read, and the address is returned without
+// allocations by Entry.AddressString("\n"). Entry.AddressLines splits the lines
+// when they are needed. Empty address lines are not kept.
+// Entries added with UpdateEntry are stored as given.
+// It can be combined with WithPackedStrings.
+func WithJoinedAddresses() Option {
+	return func(o *options) {
+		o.joinAddresses = true
+	}
+}
+
+// WithProgress will call fn with the number of records parsed so far
+// for every n records parsed while loading.
+// If n is 0 or less, fn will be called for every 1000 records.
+func WithProgress(n int, fn func(parsed int)) Option {
+	return func(o *options) {
+		if n <= 0 {
+			n = 1000
+		}
+		o.progress = fn
+		o.progressEvery = n
+	}
+}
+
+// WithKeepDuplicates will keep all entries when several entries share a prefix.
+// LookUp returns the last entry read, and the others are returned by LookUpCandidates.
+// By default only the last entry read for a prefix is kept.
+func WithKeepDuplicates() Option {
+	return func(o *options) {
+		o.keepDuplicates = true
+	}
+}
+
+// WithParseReport will fill r with statistics about the parsed file,
+// like the number of records and the ignored content.
+// When used with a dynamic database, r is also filled on updates.
+func WithParseReport(r *ParseReport) Option {
+	return func(o *options) {
+		o.report = r
+	}
+}
+
+// WithMaxEntries will stop loading once n entries have been read,
+// so memory usage is bounded regardless of the size of the input.
+// Entries that are left out by WithRegistryFilter are not counted.
+// Lookups for entries that were not loaded return ErrNotFound.
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// WithRegistryFilter will only load entries assigned from the given registries,
+// for instance only RegistryMAL to leave out the smaller assignments.
+// The registry of an entry is found with Entry.Registry.
+// Lookups for entries that were not loaded return ErrNotFound.
+func WithRegistryFilter(registries ...Registry) Option {
+	return func(o *options) {
+		o.registries = make(map[Registry]struct{}, len(registries))
+		for _, r := range registries {
+			o.registries[r] = struct{}{}
+		}
+	}
+}
+
+// WithCapacityHint will allocate room for n entries before the database is loaded,
+// so the index doesn't have to grow while it is read.
+// Room is allocated for n OUIs in the index kept by the database,
+// and for n entries while the file is read.
+// The hint is also used when the database is updated.
+// The oui.txt file published by the IEEE has around 35000 entries.
+func WithCapacityHint(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.capacity = n
+		}
+	}
+}
+
+// Return an empty index with room for the entries given by WithCapacityHint.
+func (o options) newDB() ouiDB {
+	return make(ouiDB, o.capacity)
+}
+
+// DefaultMaxAddressLines is the number of address lines kept for an entry,
+// unless another limit is given with WithMaxAddressLines.
+// Registrations published by the IEEE have less than 10 lines.
+const DefaultMaxAddressLines = 64
+
+// WithMaxAddressLines will keep at most n address lines for each entry,
+// so a malformed file cannot use unbounded memory for a single entry.
+// Additional lines are skipped, but the country is still read from the last line.
+// Entries that were truncated are counted in the ParseReport.
+// If n is 0 or less, all address lines are kept.
+// By default DefaultMaxAddressLines lines are kept.
+func WithMaxAddressLines(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			n = -1
+		}
+		o.maxAddress = n
+	}
+}
+
+// WithLenientParsing will skip records that cannot be decoded, instead of
+// failing to load the file. The errors are added to ParseReport.Warnings,
+// if a report is given with WithParseReport.
+// The database is returned with the records that could be decoded.
+// Errors reading the input are still returned.
+func WithLenientParsing() Option {
+	return func(o *options) {
+		o.lenient = true
+	}
+}
+
+// If lenient parsing is enabled, add err to the report and return true,
+// so the caller can skip the record. Otherwise err should be returned.
+func (o options) warn(report *ParseReport, err error) bool {
+	if !o.lenient {
+		return false
+	}
+	if report != nil {
+		report.Warnings = append(report.Warnings, err)
+	}
+	return true
+}
+
+// WithSourceLines will keep the text each entry was read from,
+// so it can be returned by Entry.SourceLine when debugging odd entries.
+// This keeps a copy of the input in memory, so it should only be
+// used when needed. Only oui.txt and manuf files have source lines.
+func WithSourceLines() Option {
+	return func(o *options) {
+		o.sourceLines = true
+	}
+}
+
+// WithSource will set the Source of all loaded entries to label,
+// so entries can be traced back to where they were read from when
+// databases are merged, for instance with OpenArchive.
+func WithSource(label string) Option {
+	return func(o *options) {
+		o.source = label
+	}
+}
+
+// WithClock will use clock to get the time a database is loaded or updated,
+// which is returned by LoadedAt.
+// By default SystemClock is used.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// The current time according to the clock of the options.
+func (o options) now() time.Time {
+	if o.clock == nil {
+		return SystemClock.Now()
+	}
+	return o.clock.Now()
+}
+
+// errLoadLimit is returned internally when WithMaxEntries has been reached.
+var errLoadLimit = errors.New("load limit reached")
+
+// Returns errLoadLimit if no more entries should be loaded,
+// and false if e should be left out.
+func (o options) accept(e Entry, loaded int) (bool, error) {
+	if o.registries != nil {
+		if _, ok := o.registries[e.Registry()]; !ok {
+			return false, nil
+		}
+	}
+	if o.maxEntries > 0 && loaded >= o.maxEntries {
+		return false, errLoadLimit
+	}
+	return true, nil
+}
+
+// Read a file in the format of o into db using the given options.
+// Files are read as oui.txt, unless the database was opened from another format.
+// If duplicates are kept they are returned.
+func load(in io.Reader, db ouiDB, o options) (*time.Time, duplicates, error) {
+	switch o.format {
+	case FormatCSV:
+		return loadRecords(func(fn recordFunc) (*time.Time, error) {
+			return nil, scanCSV(in, o, fn)
+		}, db, o)
+	case FormatJSONLines:
+		return loadRecords(func(fn recordFunc) (*time.Time, error) {
+			return nil, scanJSONLines(in, o, fn)
+		}, db, o)
+	}
+	if !o.sourceLines {
+		return loadRecords(func(fn recordFunc) (*time.Time, error) {
+			return scanRecords(in, o, fn)
+		}, db, o)
+	}
+	// The scanner reads ahead, so the record is in raw when it is returned.
+	var raw bytes.Buffer
+	in = io.TeeReader(in, &raw)
+	return loadRecords(func(fn recordFunc) (*time.Time, error) {
+		return scanRecords(in, o, func(e Entry, off, n int64) error {
+			e.sourceLine = strings.TrimRight(string(raw.Bytes()[off:off+n]), "\r\n")
+			return fn(e, off, n)
+		})
+	}, db, o)
+}
+
+// recordFunc is called for each record read, with the offset
+// and length of the record in the input.
+type recordFunc func(e Entry, off, n int64) error
+
+// Read the records returned by scan into db using the given options.
+// If duplicates are kept they are returned.
+func loadRecords(scan func(fn recordFunc) (*time.Time, error), db ouiDB, o options) (*time.Time, duplicates, error) {
+	var dups duplicates
+	parsed, loaded := 0, 0
+	t, err := scan(func(e Entry, off, n int64) error {
+		parsed++
+		if o.progress != nil && parsed%o.progressEvery == 0 {
+			o.progress(parsed)
+		}
+		if ok, err := o.accept(e, loaded); !ok {
+			return err
+		}
+		loaded++
+		if o.source != "" {
+			e.Source = o.source
+		}
+		if o.joinAddresses {
+			e.joinAddress()
+		}
+		k := e.Assignment()
+		if prev, ok := db[k]; ok && o.keepDuplicates {
+			if dups == nil {
+				dups = make(duplicates)
+			}
+			dups[k] = append(dups[k], prev)
+		}
+		db[k] = e
+		return nil
+	})
+	if err == errLoadLimit {
+		err = nil
+	}
+	if o.packStrings {
+		db.pack()
+	}
+	return t, dups, err
+}
+
+// Pack all strings of the entries into a single string,
+// and all address lines into a single slice.
+// Substrings share memory with the string they are sliced from,
+// so no copies are made when entries are returned.
+func (db ouiDB) pack() {
+	keys := make([]Prefix, 0, len(db))
+	size, lines := 0, 0
+	for k, e := range db {
+		keys = append(keys, k)
+		size += len(e.Manufacturer) + len(e.Country) + len(e.address)
+		lines += len(e.Address)
+		for _, a := range e.Address {
+			size += len(a)
+		}
+	}
+	// The country is usually the last address line, so it can be shared.
+	sharedCountry := func(e Entry) bool {
+		return len(e.Address) > 0 && e.Country == e.Address[len(e.Address)-1] ||
+			e.Country != "" && e.Country == e.lastAddressLine()
+	}
+
+	var b strings.Builder
+	b.Grow(size)
+	for _, k := range keys {
+		e := db[k]
+		b.WriteString(e.Manufacturer)
+		for _, a := range e.Address {
+			b.WriteString(a)
+		}
+		b.WriteString(e.address)
+		if !sharedCountry(e) {
+			b.WriteString(e.Country)
+		}
+	}
+
+	all := b.String()
+	pos := 0
+	next := func(n int) string {
+		s := all[pos : pos+n]
+		pos += n
+		return s
+	}
+	addr := make([]string, 0, lines)
+	for _, k := range keys {
+		e := db[k]
+		shared := sharedCountry(e)
+		e.Manufacturer = next(len(e.Manufacturer))
+		if e.Address != nil {
+			start := len(addr)
+			for _, a := range e.Address {
+				addr = append(addr, next(len(a)))
+			}
+			// Limit capacity, so appending to an entry cannot overwrite the next.
+			e.Address = addr[start:len(addr):len(addr)]
+		}
+		e.address = next(len(e.address))
+		switch {
+		case shared && e.Address != nil:
+			e.Country = e.Address[len(e.Address)-1]
+		case shared:
+			e.Country = e.lastAddressLine()
+		default:
+			e.Country = next(len(e.Country))
+		}
+		db[k] = e
+	}
+}
@@ -0,0 +1,180 @@
+package oui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// A registry in the oui.txt format with n MA-L entries, around the size of
+// the file published by the IEEE for n = 35000.
+func registryText(n int) []byte {
+	var b []byte
+	for i := 0; i < n; i++ {
+		// The lowest two bits of the first byte are kept clear.
+		p := uint32(i) << 2
+		name := fmt.Sprintf("Vendor %d Corp.", i)
+		b = fmt.Appendf(b, "%02X-%02X-%02X   (hex)\t\t%s\r\n", byte(p>>16), byte(p>>8), byte(p), name)
+		b = fmt.Appendf(b, "%02X%02X%02X     (base 16)\t\t%s\r\n", byte(p>>16), byte(p>>8), byte(p), name)
+		b = fmt.Appendf(b, "\t\t\t\t%d Industrial Road\r\n\t\t\t\tSpringfield  %05d\r\n\t\t\t\tUS\r\n\r\n", i, i%100000)
+	}
+	return b
+}
+
+func TestJoinedAddresses(t *testing.T) {
+	b, err := os.ReadFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := OpenBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range [][]Option{
+		{WithJoinedAddresses()},
+		{WithJoinedAddresses(), WithPackedStrings()},
+	} {
+		db, err := OpenBytes(b, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Equal(db, want) {
+			t.Error("the entries differ from a database with address lines")
+		}
+		hw := HardwareAddr{0x00, 0x22, 0x72}
+		e, err := db.LookUp(hw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := []string{"2181 Buchanan Loop", "Ferndale  WA  98248", "US"}
+		if e.Address != nil || !reflect.DeepEqual(e.AddressLines(), lines) {
+			t.Errorf("Address = %q, AddressLines = %q, want nil and %q", e.Address, e.AddressLines(), lines)
+		}
+		if got := e.AddressString("\n"); got != strings.Join(lines, "\n") {
+			t.Errorf("AddressString = %q", got)
+		}
+		if got := e.AddressString(", "); got != strings.Join(lines, ", ") {
+			t.Errorf("AddressString(\", \") = %q", got)
+		}
+		if e.Country != "US" {
+			t.Errorf("Country = %q, want US", e.Country)
+		}
+		if n := testing.AllocsPerRun(100, func() { e.AddressString("\n") }); n != 0 {
+			t.Errorf("AddressString allocates %v times", n)
+		}
+		j, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(j, []byte(`"address":["2181 Buchanan Loop","Ferndale  WA  98248","US"]`)) {
+			t.Errorf("JSON %s is missing the address lines", j)
+		}
+		var got, exp bytes.Buffer
+		if err := ExportFiltered(db, &got, nil, FormatOUI); err != nil {
+			t.Fatal(err)
+		}
+		if err := ExportFiltered(want, &exp, nil, FormatOUI); err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != exp.String() {
+			t.Error("the exported file differs from a database with address lines")
+		}
+	}
+}
+
+// Report the heap objects kept by the database, and the time
+// a garbage collection takes while it is loaded.
+func BenchmarkPackedStrings(b *testing.B) {
+	text := registryText(35000)
+	for _, test := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "packed", opts: []Option{WithPackedStrings()}},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			runtime.GC()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			db, err := OpenBytes(text, test.opts...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				runtime.GC()
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(after.HeapObjects)-float64(before.HeapObjects), "heap-objects")
+			runtime.KeepAlive(db)
+		})
+	}
+}
+
+// Report the heap used by the database, and the allocations
+// to print the address of an entry.
+func BenchmarkAddressStorage(b *testing.B) {
+	text := registryText(35000)
+	for _, test := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "lines"},
+		{name: "packed", opts: []Option{WithPackedStrings()}},
+		{name: "joined", opts: []Option{WithJoinedAddresses()}},
+		{name: "joined-packed", opts: []Option{WithJoinedAddresses(), WithPackedStrings()}},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			runtime.GC()
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			db, err := OpenBytes(text, test.opts...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			e, err := db.LookUp(HardwareAddr{0x00, 0x01, 0x00})
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = e.AddressString("\n")
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(after.HeapAlloc)-float64(before.HeapAlloc), "heap-bytes")
+			runtime.KeepAlive(db)
+		})
+	}
+}
+
+func BenchmarkCapacityHint(b *testing.B) {
+	text := registryText(35000)
+	for _, test := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "none"},
+		{name: "hint", opts: []Option{WithCapacityHint(35000)}},
+	} {
+		b.Run(test.name, func(b *testing.B) {
+			b.SetBytes(int64(len(text)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := OpenBytes(text, test.opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
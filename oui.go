@@ -25,29 +25,49 @@ package oui
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math/bits"
 	"net/http"
 	"os"
+	"path"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Internal representation of the database content
-type ouiDB map[[3]byte]Entry
+// Internal representation of the database content,
+// keyed by the assignment of the entries.
+type ouiDB map[Prefix]Entry
 
 // Set an element to contain this value
-func (db ouiDB) set(hw HardwareAddr, e Entry) {
-	db[[3]byte(hw)] = e
+func (db ouiDB) set(e Entry) {
+	db[e.Assignment()] = e
+}
+
+// Call fn for all elements until it returns false.
+func (db ouiDB) walk(fn func(Entry) bool) error {
+	for _, e := range db {
+		if !fn(e) {
+			break
+		}
+	}
+	return nil
 }
 
 // Delete an element. If the element does not exist,
 // the function will just return.
-func (db ouiDB) del(hw HardwareAddr) {
-	delete(db, [3]byte(hw))
+func (db ouiDB) del(k Prefix) {
+	delete(db, k)
 }
 
 // This interface can be used to access the raw
@@ -58,25 +78,105 @@ type RawGetter interface {
 
 // ErrNotFound will be returned when LookUp function fails
 // to find the entry in the database.
+// LookUp returns a NotFoundError, so use errors.Is(err, ErrNotFound) to check for it.
 var ErrNotFound = errors.New("not found")
 
-// OuiDB represents a database that allow you to look up Hardware Addresses
-type OuiDB interface {
+// NotFoundError is returned when an address cannot be found in the database.
+// It carries the address that was looked up, and wraps ErrNotFound.
+type NotFoundError struct {
+	Addr HardwareAddr
+}
+
+// Error returns a string representation of the error.
+func (e NotFoundError) Error() string {
+	return e.Addr.String() + " " + ErrNotFound.Error()
+}
+
+// Unwrap returns ErrNotFound.
+func (e NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// ErrNotUniversal is wrapped by the errors returned by LookUpUniversal
+// when the address is not universally administered.
+var ErrNotUniversal = errors.New("not a universally administered address")
+
+// ErrLocallyAdministered will be returned by LookUpUniversal for
+// locally administered addresses that are not in the database.
+var ErrLocallyAdministered = fmt.Errorf("locally administered address: %w", ErrNotUniversal)
+
+// ErrMulticast will be returned by LookUpUniversal for
+// multicast and broadcast addresses.
+var ErrMulticast = fmt.Errorf("multicast address: %w", ErrNotUniversal)
+
+// ReadOnlyDB is the part of a database needed to look up Hardware Addresses.
+// Unlike OuiDB it can be implemented outside this package,
+// for instance by databases stored on disk.
+type ReadOnlyDB interface {
 	// Query the database for an entry based on the mac address
-	// If none are found ErrNotFound will be returned.
+	// If none are found a NotFoundError will be returned.
 	Query(string) (*Entry, error)
 
 	// Look up a hardware address and return the entry if any are found.
-	// If none are found ErrNotFound will be returned.
+	// If none are found a NotFoundError will be returned.
 	LookUp(HardwareAddr) (*Entry, error)
 
 	// Returns the generation time of the database
 	// May return the zero time if unparsable
 	Generated() time.Time
+}
+
+// OuiDB represents a database that allow you to look up Hardware Addresses.
+// The functions of this package taking an OuiDB, like Search and Coalesce,
+// work the same for all database types.
+type OuiDB interface {
+	ReadOnlyDB
+
+	// Look up a hardware address and return all entries the address could belong to,
+	// most specific first. Entries with the same prefix length are ordered
+	// with the most recently read first.
+	// If none are found a NotFoundError will be returned.
+	LookUpCandidates(HardwareAddr) ([]*Entry, error)
+
+	// LookUpUint64 will look up a MAC address stored in the lower 48 bits of mac.
+	// If none are found a NotFoundError will be returned.
+	LookUpUint64(mac uint64) (*Entry, error)
+
+	// LookUpContext will look up a hardware address like LookUp,
+	// but return the error of ctx if it is done before the lookup completes.
+	LookUpContext(ctx context.Context, hw HardwareAddr) (*Entry, error)
+
+	// Iterate will call fn for all entries in the database until it returns false.
+	// Entries are given sorted by assignment, see Entry.Assignment, except for
+	// databases opened with OpenWithIndex, which are given in the order of Index.Walk.
+	Iterate(fn func(*Entry) bool) error
+
+	// Len returns the number of entries in the database.
+	Len() int
+
+	// LoadedAt returns the time the content of the database was loaded,
+	// or last replaced by an update.
+	LoadedAt() time.Time
+
+	// ApproxMemoryBytes returns an estimate of the memory used by the database.
+	ApproxMemoryBytes() int64
+
+	// SetParentMapping will set the parent organization of manufacturers,
+	// which lookups return as Entry.ParentOrganization.
+	SetParentMapping(parents map[string]string)
+
+	// Classify will return the class of a hardware address,
+	// after looking for an entry in the database.
+	Classify(HardwareAddr) AddressClass
+
+	// SetNameNormalizer will set a function used to clean manufacturer names
+	// before they are compared. Setting nil restores the built-in normalizer.
+	SetNameNormalizer(fn func(string) string)
 
 	// Internal functions
-	set(HardwareAddr, Entry)
+	walk(func(Entry) bool) error
 	generatedAt(*time.Time)
+	nameNormalizer() func(string) string
 }
 
 // StaticDB is a database containing OUI entries that doesn't
@@ -86,6 +186,11 @@ type OuiDB interface {
 type StaticDB interface {
 	OuiDB
 	RawGetter
+
+	// Close will release the resources held by the database, like files
+	// entries are read from. The database must not be used after it is closed.
+	// Closing a database more than once is safe.
+	Close() error
 }
 
 // DynamicDB is a database containing OUI entries that
@@ -99,144 +204,551 @@ type DynamicDB interface {
 // Create a new dynamic database with optional content.
 // You can pass nil as parameter, which will initialize the database.
 // A database returned from this can be expected to implement the Updater interface.
-func newDynamic(c map[[3]byte]Entry) DynamicDB {
-	if c == nil {
-		c = make(map[[3]byte]Entry)
-	}
-	return &updateableDB{ouiDB: c}
+func newDynamic(c ouiDB, dups duplicates, o options) DynamicDB {
+	return updateableDB{newDatabase(newMemStore(c, dups, o.capacity), o)}
 }
 
 // Create a new static database with optional content.
 // You can pass nil as parameter, which will initialize the database.
 // A database returned from this can be expected to implement the RawGetter interface.
-func newStatic(c map[[3]byte]Entry) StaticDB {
-	if c == nil {
-		c = make(map[[3]byte]Entry)
-	}
-	return &staticDB{ouiDB: c}
+func newStatic(c ouiDB, dups duplicates, o options) StaticDB {
+	return staticDB{newDatabase(newMemStore(c, dups, o.capacity), o)}
+}
+
+// The implementation shared by all database types,
+// which differ in how the entries are stored.
+// There is a mutex protecting read/write access to the store.
+type database struct {
+	st     store
+	dbTime time.Time
+	loaded time.Time
+	mu     sync.RWMutex
+	opts   options
+	// The number of walks in progress on a snapshot of the store.
+	walking int32
+	parents parentMap
+	// Set with SetNameNormalizer, nil for the built-in normalizer.
+	normalizer func(string) string
+
+	// The file entries are read from, if any, closed by Close.
+	file      io.Closer
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Create a database with the entries of st.
+func newDatabase(st store, o options) *database {
+	return &database{st: st, opts: o, loaded: o.now()}
 }
 
 // A static database
 type staticDB struct {
-	ouiDB
-	dbTime time.Time
+	*database
+}
+
+// An updateable database.
+type updateableDB struct {
+	*database
 }
 
 // Check we implement the interfaces we promise
-var _ OuiDB = &staticDB{}
-var _ RawGetter = staticDB{}
+var _ StaticDB = staticDB{}
+var _ DynamicDB = updateableDB{}
+var _ io.Closer = updateableDB{}
 
-// Satisfy the RawGetter interface
+// Satisfy the RawGetter interface.
+// The map is keyed by the OUI, so only entries assigned 24 bits or less
+// are included, and MA-M and MA-S entries are left out.
+// If a prefix of less than 24 bits has the OUI of a 24 bit entry, the 24 bit entry is kept.
+// The map is built by the call, so modifying it doesn't change the database.
 func (db staticDB) RawDB() map[[3]byte]Entry {
-	return db.ouiDB
+	dst := make(map[[3]byte]Entry, db.Len())
+	db.walk(func(e Entry) bool {
+		if old, ok := dst[e.Prefix]; e.bits() <= 24 && (!ok || old.bits() < e.bits()) {
+			dst[e.Prefix] = e
+		}
+		return true
+	})
+	return dst
 }
 
-// Query the database for an entry based on the mac address
-// If none are found ErrNotFound will be returned.
-func (db staticDB) Query(mac string) (*Entry, error) {
-	hw, err := ParseMac(mac)
+// Close will close the file the entries are read from, if any.
+// Databases held in memory have nothing to close.
+// It is safe to call it several times.
+func (db *database) Close() error {
+	db.closeOnce.Do(func() {
+		if db.file != nil {
+			db.closeErr = db.file.Close()
+		}
+	})
+	return db.closeErr
+}
+
+// Query the database for an entry based on the mac address.
+// All octets given are used, so a complete address like "70:b3:d5:f0:00:01"
+// returns the MA-S entry for 70:b3:d5:f0:00:00/36 if it is in the database,
+// while "70:b3:d5" is looked up like LookUp.
+// If none are found a NotFoundError will be returned.
+func (db *database) Query(mac string) (*Entry, error) {
+	octets, err := parseOctets(mac, 6)
 	if err != nil {
 		return nil, err
 	}
-	return db.LookUp(*hw)
+	p := Prefix{Bits: len(octets) * 8}
+	copy(p.Addr[:], octets)
+	return db.lookUp(p)
 }
 
 // LookUp a hardware address and return the entry if any are found.
-// If none are found ErrNotFound will be returned.
-func (o staticDB) LookUp(hw HardwareAddr) (*Entry, error) {
-	e, ok := o.ouiDB[hw]
+// Only the 24 bits of the hardware address are known, so the entry with the
+// longest assignment of 24 bits or less is returned. If the OUI is subdivided
+// into MA-M or MA-S assignments, the entry has ConfidenceLow.
+// Use LookUpUint64 or Query with a complete address to find those assignments.
+// If none are found a NotFoundError will be returned, also if the OUI only
+// has MA-M or MA-S entries; use LookUpCandidates to get those.
+// Errors reading the entry from where it is stored are returned as is.
+func (db *database) LookUp(hw HardwareAddr) (*Entry, error) {
+	return db.lookUp(prefixOf(hw, 24))
+}
+
+// Look up the entry with the longest assignment containing p.
+func (db *database) lookUp(p Prefix) (*Entry, error) {
+	db.mu.RLock()
+	e, ok, err := db.st.get(p)
+	subdivided := ok && err == nil && db.st.hasLonger(p)
+	e.normalizer = db.normalizer
+	db.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
 	if !ok {
-		return nil, ErrNotFound
+		return nil, NotFoundError{Addr: p.OUI()}
 	}
+	setConfidence(&e, subdivided)
+	db.parents.apply(&e)
 	return &e, nil
 }
 
-// Get the generated time
-func (o staticDB) Generated() time.Time {
-	return time.Time(o.dbTime)
+// LookUpCandidates returns all entries the hardware address could belong to,
+// most specific first. These are the entries assigned longer prefixes
+// within the OUI, like the MA-S entries of 70:b3:d5, and the entry returned by LookUp.
+// If the database was loaded with WithKeepDuplicates, the entries shadowed by
+// them are also returned.
+// Entries with the same prefix length are ordered by prefix,
+// and then with the most recently read first.
+// If none are found a NotFoundError will be returned.
+func (db *database) LookUpCandidates(hw HardwareAddr) ([]*Entry, error) {
+	p := prefixOf(hw, 24)
+	var res []*Entry
+	db.mu.RLock()
+	add := func(e Entry) {
+		res = append(res, &e)
+		// Most recently read first.
+		dups := db.st.shadowed(e.Assignment())
+		for i := len(dups) - 1; i >= 0; i-- {
+			e := dups[i]
+			res = append(res, &e)
+		}
+	}
+	var longer []Entry
+	err := db.st.longer(p, func(e Entry) bool {
+		longer = append(longer, e)
+		return true
+	})
+	sort.Slice(longer, func(i, j int) bool {
+		return longer[i].Assignment().less(longer[j].Assignment())
+	})
+	for _, e := range longer {
+		add(e)
+	}
+	if err == nil {
+		var e Entry
+		var ok bool
+		if e, ok, err = db.st.get(p); ok {
+			add(e)
+		}
+	}
+	normalizer := db.normalizer
+	db.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, NotFoundError{Addr: hw}
+	}
+	for _, e := range res {
+		e.normalizer = normalizer
+		db.parents.apply(e)
+		if len(res) > 1 || e.bits() > 24 {
+			e.Confidence = ConfidenceLow
+		} else {
+			setConfidence(e, false)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].bits() > res[j].bits()
+	})
+	return res, nil
 }
 
-// Update "generated at" time
-func (d *staticDB) generatedAt(t *time.Time) {
-	if t == nil {
-		return
-	}
-	d.dbTime = *t
+// LookUpUint64 will look up a MAC address stored in the lower 48 bits of mac,
+// so 0x0060929802ff is looked up as 00:60:92:98:02:ff.
+// All 48 bits are used, so MA-M and MA-S entries are found.
+// If none are found a NotFoundError will be returned.
+func (db *database) LookUpUint64(mac uint64) (*Entry, error) {
+	return db.lookUp(prefixUint64(mac))
 }
 
-// An updateable database.
-// There is a mutex protecting read/write access to the database.
-type updateableDB struct {
-	ouiDB
-	dbTime time.Time
-	mu     sync.RWMutex
+// LookUpContext will look up a hardware address like LookUp.
+// If ctx is done before the lookup, the error of ctx is returned.
+// Lookups of entries held in memory cannot block, so ctx is only checked before them.
+// Entries read from a file are read in the background, and the lookup
+// returns when ctx is done, also if the read hasn't completed.
+func (db *database) LookUpContext(ctx context.Context, hw HardwareAddr) (*Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.RLock()
+	blocking := db.st.blocking()
+	db.mu.RUnlock()
+	if !blocking {
+		return db.LookUp(hw)
+	}
+	type result struct {
+		e   *Entry
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		e, err := db.LookUp(hw)
+		done <- result{e: e, err: err}
+	}()
+	select {
+	case r := <-done:
+		return r.e, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
-// Check we implement the interfaces we promise
-var _ Updater = &updateableDB{}
-var _ OuiDB = &updateableDB{}
+// Iterate will call fn for all entries in the database until it returns false.
+// Entries are given sorted by assignment, see Entry.Assignment, except for
+// databases opened with OpenWithIndex, which are given in the order of Index.Walk.
+// The entries are those in the database when Iterate is called.
+// Updates made while iterating, also by fn, don't affect the iteration.
+func (db *database) Iterate(fn func(*Entry) bool) error {
+	return db.walk(func(e Entry) bool {
+		return fn(&e)
+	})
+}
 
-// Query the database for an entry based on the mac address
-// If none are found ErrNotFound will be returned.
-func (db *updateableDB) Query(mac string) (*Entry, error) {
-	hw, err := ParseMac(mac)
-	if err != nil {
-		return nil, err
+// Call fn for all elements until it returns false.
+// The entries are taken from a snapshot of the database, so updates
+// can be made while walking, and don't affect the walk.
+func (db *database) walk(fn func(Entry) bool) error {
+	db.mu.RLock()
+	st := db.st
+	normalizer := db.normalizer
+	atomic.AddInt32(&db.walking, 1)
+	db.mu.RUnlock()
+	defer atomic.AddInt32(&db.walking, -1)
+	if normalizer == nil {
+		return st.walk(fn)
 	}
-	return db.LookUp(*hw)
+	return st.walk(func(e Entry) bool {
+		e.normalizer = normalizer
+		return fn(e)
+	})
 }
 
-// Look up a hardware address and return the entry if any are found.
-// If none are found ErrNotFound will be returned.
-func (o *updateableDB) LookUp(hw HardwareAddr) (*Entry, error) {
-	o.mu.RLock()
-	e, ok := o.ouiDB[hw]
-	o.mu.RUnlock()
-	if !ok {
-		return nil, ErrNotFound
+// Prepare the store to be modified in place.
+// If a walk may be using the store, it is copied first,
+// so the walk keeps its snapshot.
+// db.mu must be held for writing.
+func (db *database) modify() {
+	if atomic.LoadInt32(&db.walking) == 0 {
+		return
 	}
-	return &e, nil
+	db.st = db.st.clone()
+}
+
+// ApproxMemoryBytes returns an estimate of the memory used by the database.
+// It is based on the number of entries and the length of their text,
+// so the actual usage depends on the Go runtime and can differ.
+// Entries read from a file are not counted, only the locations of their records.
+// Snapshots held by walks in progress are not counted.
+func (db *database) ApproxMemoryBytes() int64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.st.memBytes()
+}
+
+// SetParentMapping will set the parent organization of manufacturers,
+// for instance after an acquisition the registry doesn't show yet.
+// The keys are manufacturer names, compared like Entry.NormalizedManufacturer,
+// so the normalizer set with SetNameNormalizer is used, also if it is set later.
+// The values are their parents. Parents can have parents themselves,
+// and LookUp and the lookups based on it set Entry.ParentOrganization
+// to the ultimate parent. Entries without a parent are returned unchanged.
+// The mapping is copied. Setting nil removes the mapping, which is the default.
+func (db *database) SetParentMapping(parents map[string]string) {
+	db.parents.set(parents)
+}
+
+// SetNameNormalizer will set a function used to clean manufacturer names
+// before they are compared, for instance to remove suffixes like "Inc." or
+// to map known aliases to a single name.
+// It is applied by Search to both the query and the manufacturers,
+// before case folding and accent removal, and by Entry.NormalizedManufacturer
+// of the entries returned by the database, instead of the built-in whitespace
+// cleaning and case folding. SameVendor, VendorPrefixCounts and Coalesce
+// compare names with NormalizedManufacturer, so they use it too.
+// The function is not applied when entries are loaded, so the stored
+// names are never modified, and it doesn't affect other databases.
+// Setting nil restores the built-in normalizer, which is the default.
+// It is safe to call while the database is used, but results
+// computed before the call are not updated.
+func (db *database) SetNameNormalizer(fn func(string) string) {
+	db.mu.Lock()
+	db.normalizer = fn
+	db.parents.setNormalizer(fn)
+	db.mu.Unlock()
+}
+
+// The normalizer set with SetNameNormalizer, or nil.
+func (db *database) nameNormalizer() func(string) string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.normalizer
 }
 
 // Get the generated time
-func (o *updateableDB) Generated() time.Time {
-	o.mu.RLock()
-	defer o.mu.RUnlock()
-	return o.dbTime
+func (db *database) Generated() time.Time {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.dbTime
+}
+
+// LoadedAt returns the time the content of the database was loaded,
+// or last replaced by an update.
+func (db *database) LoadedAt() time.Time {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.loaded
+}
+
+// Len returns the number of entries in the database.
+func (db *database) Len() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.st.len()
 }
 
 // Update "generated at" time
-// Assumes updateableDB mutex is locked by caller.
-func (o *updateableDB) generatedAt(t *time.Time) {
+// Assumes the mutex is locked by caller.
+func (db *database) generatedAt(t *time.Time) {
 	if t == nil {
 		return
 	}
-	o.dbTime = *t
+	db.dbTime = *t
+}
+
+// The store of an updateable database, which can always be written.
+func (o updateableDB) writable() writableStore {
+	return o.st.(writableStore)
 }
 
 // Update the database and replace content with the supplied content.
-func (o *updateableDB) updateDb(db ouiDB, t *time.Time) {
+// Entries are no longer read from a file after this.
+// If the store cannot be written, the error is returned, and the
+// generation and load times are not changed.
+func (o updateableDB) updateDb(db ouiDB, dups duplicates, t *time.Time) error {
 	o.mu.Lock()
-	o.ouiDB = db
+	defer o.mu.Unlock()
+	st, err := o.writable().replace(db, dups)
+	if err != nil {
+		return err
+	}
+	o.st = st
+	o.loaded = o.opts.now()
 	o.generatedAt(t)
-	o.mu.Unlock()
+	return nil
+}
+
+// The options to use when the database is updated.
+func (o updateableDB) options() options {
+	return o.opts
 }
 
 // UpdateEntry will update/add a single entry to the database.
-func (o *updateableDB) UpdateEntry(hw HardwareAddr, e Entry) {
+// The prefix of the entry is set to hw, and it is stored for the
+// assignment with the extension and prefix length of the entry.
+// Other entries kept for the assignment are removed.
+// Errors from an Index are ignored, see OpenWithIndex.
+func (o updateableDB) UpdateEntry(hw HardwareAddr, e Entry) {
+	e.Prefix = hw
 	o.mu.Lock()
-	o.ouiDB.set(hw, e)
+	o.modify()
+	o.writable().set(e)
 	o.mu.Unlock()
 }
 
-// DeleteEntry will remove an entry from the database.
+// Replace the entries held in memory with the entries returned by fn,
+// if it returns true. The entries must be equal, since the
+// entries shadowed by them are kept. Used by VersionedDB.
+func (o updateableDB) share(fn func(e Entry) (Entry, bool)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.modify()
+	ms, ok := o.st.(*memStore)
+	if !ok {
+		o.st.walk(func(e Entry) bool {
+			fn(e)
+			return true
+		})
+		return
+	}
+	var replace []Entry
+	ms.db.walk(func(_ Prefix, e Entry) bool {
+		if s, ok := fn(e); ok {
+			replace = append(replace, s)
+		}
+		return true
+	})
+	for _, e := range replace {
+		ms.db.set(e.Assignment(), e)
+	}
+}
+
+// DeleteEntry will remove the 24 bit entry of hw from the database.
+// Entries assigned other prefix lengths are kept, they can be removed with ApplyDelta.
 // If the element does not exist, the function will just return.
-func (o *updateableDB) DeleteEntry(hw HardwareAddr) {
+// Errors from an Index are ignored, see OpenWithIndex.
+func (o updateableDB) DeleteEntry(hw HardwareAddr) {
 	o.mu.Lock()
-	o.ouiDB.del(hw)
+	o.modify()
+	o.writable().del(prefixOf(hw, 24))
+	o.mu.Unlock()
+}
+
+// Reset will remove all entries and the generation time from the database,
+// so it can be loaded again with the Update functions.
+// The options the database was opened with are kept.
+// Queries running at the same time will either see the old content
+// or an empty database, and walks in progress keep their snapshot.
+// Like an update, this stops reading entries from a file.
+// All entries are deleted from an Index, ignoring errors.
+func (o updateableDB) Reset() {
+	o.mu.Lock()
+	// Walks may hold the old store, so it is replaced, not cleared.
+	if st, err := o.writable().replace(make(ouiDB), nil); err == nil {
+		o.st = st
+	}
+	o.dbTime = time.Time{}
+	o.loaded = time.Time{}
 	o.mu.Unlock()
 }
 
+// LookUpLevels will look up a hardware address, only considering entries
+// assigned with one of the given prefix lengths, for instance 24 for MA-L entries only.
+// Entries without a known prefix length are considered to be 24 bits.
+// Only the 24 bits of the hardware address are known, so MA-M and MA-S
+// entries are never returned. Use LookUpLevelsUint64 to consider them.
+// If no lengths are given, all entries are considered.
+// If none are found a NotFoundError will be returned.
+func LookUpLevels(db OuiDB, hw HardwareAddr, bits ...int) (*Entry, error) {
+	return lookUpLevels(db, prefixOf(hw, 24), bits)
+}
+
+// LookUpLevelsUint64 will look up a MAC address stored in the lower 48 bits of mac
+// like LookUpUint64, only considering entries assigned with one of the given
+// prefix lengths, so LookUpLevelsUint64(db, mac, 24) ignores MA-M and MA-S entries.
+// The most specific entry containing the address is returned.
+// If no lengths are given, all entries are considered.
+// If none are found a NotFoundError will be returned.
+func LookUpLevelsUint64(db OuiDB, mac uint64, bits ...int) (*Entry, error) {
+	return lookUpLevels(db, prefixUint64(mac), bits)
+}
+
+// Return the most specific candidate containing p assigned with one of the lengths.
+func lookUpLevels(db OuiDB, p Prefix, bits []int) (*Entry, error) {
+	c, err := db.LookUpCandidates(p.OUI())
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range c {
+		if !e.Assignment().contains(p) || !hasLength(bits, e.bits()) {
+			continue
+		}
+		// The entry is scored like a lookup of p, so other entries for the
+		// same or longer assignments overlapping p make it less certain.
+		subdivided := false
+		for _, o := range c {
+			a := o.Assignment()
+			subdivided = subdivided || o != e && o.bits() >= e.bits() && (a.contains(p) || p.contains(a))
+		}
+		setConfidence(e, subdivided)
+		return e, nil
+	}
+	return nil, NotFoundError{Addr: p.OUI()}
+}
+
+// Returns true if no lengths are given, or n is one of them.
+func hasLength(lengths []int, n int) bool {
+	for _, l := range lengths {
+		if l == n {
+			return true
+		}
+	}
+	return len(lengths) == 0
+}
+
+// LookUpUniversal will look up a universally administered hardware address.
+// ErrMulticast is returned for multicast and broadcast addresses, and
+// ErrLocallyAdministered for locally administered addresses,
+// unless the database has an entry for the address, like one added to an overlay.
+// Both can be checked with errors.Is(err, ErrNotUniversal).
+// Otherwise it behaves like LookUp.
+func LookUpUniversal(db ReadOnlyDB, hw HardwareAddr) (*Entry, error) {
+	if hw.Multicast() {
+		return nil, ErrMulticast
+	}
+	if hw.Local() {
+		// Organizations can register their own locally administered prefixes.
+		if e, err := db.LookUp(hw); err == nil {
+			return e, nil
+		}
+		return nil, ErrLocallyAdministered
+	}
+	return db.LookUp(hw)
+}
+
+// LookUpBSSID will look up a Wi-Fi BSSID like LookUp, but if the address is
+// locally administered, the bit is cleared before the lookup.
+// Access points often derive the BSSIDs of additional networks from the address
+// of the radio by setting the locally administered bit, so this returns the vendor
+// of the radio. This is only a heuristic: the other bits the access point changed
+// are not restored, and a random local address will also return a vendor.
+// Entries found with the bit cleared have ConfidenceVeryLow. If none is found,
+// the address is looked up unchanged.
+func LookUpBSSID(db ReadOnlyDB, hw HardwareAddr) (*Entry, error) {
+	if !hw.Local() {
+		return db.LookUp(hw)
+	}
+	radio := hw
+	radio[0] &^= 0x02
+	e, err := db.LookUp(radio)
+	if err == nil {
+		e.Confidence = ConfidenceVeryLow
+		return e, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	// The address may be registered as a locally administered prefix.
+	return db.LookUp(hw)
+}
+
 // The Updater interface will be satisfied if the database was opened as a dynamic database.
 // This can be used to safely update the database, even while queries are running.
 type Updater interface {
@@ -246,22 +758,120 @@ type Updater interface {
 	// DeleteEntry will remove an entry from the database. If the element does not exist, nothing should happen
 	DeleteEntry(HardwareAddr)
 
-	updateDb(ouiDB, *time.Time)
+	// Reset will remove all entries and the generation time from the database,
+	// so it can be loaded again with the Update functions.
+	Reset()
+
+	// ApplyDelta will read a delta and apply it to the database.
+	// The delta format is line based, and each line starts with an operation:
+	//
+	//	"+ " followed by a JSON encoded entry that was added.
+	//	"~ " followed by a JSON encoded entry that was changed.
+	//	"- " followed by the assignment of an entry that was removed,
+	//	     like 00:60:92 or 70:b3:d5:f0:00:00/36.
+	//	"= " followed by the generation time of the new database in RFC3339 format,
+	//	     with fractional seconds if it has them.
+	//
+	// Empty lines and lines starting with '#' are ignored.
+	ApplyDelta(io.Reader) error
+
+	updateDb(ouiDB, duplicates, *time.Time) error
+	options() options
 }
 
 // Read an oui file.
 func scanOUI(in io.Reader, db ouiDB) (*time.Time, error) {
-	buffered := bufio.NewReader(in)
-	scanner := bufio.NewScanner(buffered)
-	re := regexp.MustCompile(`((?:(?:[0-9a-zA-Z]{2})[-:]){2,5}(?:[0-9a-zA-Z]{2}))(?:/(\w{1,2}))?`)
+	return scanRecords(in, options{}, func(e Entry, off, n int64) error {
+		db.set(e)
+		return nil
+	})
+}
+
+// Parse will read an oui file and call fn for every entry found.
+// No database is built, so this can be used to stream entries
+// into other storage.
+// If fn returns an error, parsing is stopped and the error is returned.
+func Parse(r io.Reader, fn func(*Entry) error) error {
+	_, err := scanRecords(r, options{}, func(e Entry, off, n int64) error {
+		return fn(&e)
+	})
+	return err
+}
+
+// ParseReport contains statistics about a parsed file.
+// Lines that are not part of a record, like headers, footers and notices,
+// are ignored when parsing.
+type ParseReport struct {
+	// Records is the number of records read.
+	Records int
+	// IgnoredLines is the number of lines outside records that were ignored.
+	IgnoredLines int
+	// FooterBytes is the number of ignored bytes after the last record.
+	FooterBytes int64
+	// TruncatedAddresses is the number of records with more address lines than were kept.
+	TruncatedAddresses int
+	// Warnings are the records that were skipped because they could not be decoded,
+	// when WithLenientParsing is used. They are usually ErrInvalidRecord.
+	Warnings []error
+}
+
+// Read an oui file and call fn for every record found.
+// The offset and length of the record in the input is supplied,
+// so the record can be located again without keeping it in memory.
+// If fn returns an error, scanning is stopped and the error is returned.
+// The report, address limit and lenient parsing are taken from o.
+// The report is filled with statistics about the input, if it is set.
+// If no address limit is set, DefaultMaxAddressLines is used.
+// Records are found by the prefix starting the line, not by the "(hex)"
+// and "(base 16)" markers, so files with the markers in another case are read the same.
+// The range on the "(base 16)" line of mam.txt and oui36.txt records, like
+// "F00000-F00FFF", sets the extension and prefix length of the entry.
+func scanRecords(in io.Reader, o options, fn func(e Entry, off, n int64) error) (*time.Time, error) {
+	report := o.report
+	if report == nil {
+		report = &ParseReport{}
+	}
+	*report = ParseReport{}
+	maxAddress := o.maxAddress
+	if maxAddress == 0 {
+		maxAddress = DefaultMaxAddressLines
+	}
+	buf := scanBuffers.Get().(*[]byte)
+	defer scanBuffers.Put(buf)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(*buf, bufio.MaxScanTokenSize)
+	// Keep track of how far we have read into the input, and the line number.
+	var pos int64
+	var lineNo int
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		pos += int64(advance)
+		if advance > 0 {
+			lineNo++
+		}
+		return advance, token, err
+	})
 	var generated *time.Time
+	// Files without blank lines between records, like the Wireshark manuf file,
+	// have the next record on the line ending the current one.
+	pending := false
+	var next int64
 
-	for scanner.Scan() {
+	for {
+		start := pos
+		if pending {
+			start = next
+		} else if !scanner.Scan() {
+			break
+		}
+		pending = false
+		line := lineNo
 		if len(scanner.Text()) == 0 || scanner.Text()[0] == '#' {
 			continue
 		}
 
-		arr := strings.Split(scanner.Text(), "\t")
+		// Trailing whitespace would leave an empty manufacturer.
+		arr := strings.Split(strings.TrimRight(scanner.Text(), " \t\r"), "\t")
 		if len(arr) == 0 {
 			continue
 		}
@@ -269,7 +879,7 @@ func scanOUI(in io.Reader, db ouiDB) (*time.Time, error) {
 		t0 := strings.TrimSpace(arr[0])
 		if strings.HasPrefix(t0, "Generated: ") {
 			t0 = t0[11:]
-			t, err := time.Parse("Mon, 2 Jan 2006 15:04:05 -0700", t0)
+			t, err := time.Parse(generatedFormat, t0)
 			// We ignore the error
 			if err == nil {
 				generated = &t
@@ -277,55 +887,154 @@ func scanOUI(in io.Reader, db ouiDB) (*time.Time, error) {
 			continue
 
 		}
-		matches := re.FindAllStringSubmatch(arr[0], -1)
+		matches := prefixRe.FindAllStringSubmatch(arr[0], -1)
 		if len(matches) == 0 {
+			// Not part of a record, like the header or a footer.
+			report.IgnoredLines++
+			report.FooterBytes += pos - start
 			continue
 		}
 
 		s := matches[0][1]
 
+		// The rest of the record is read, also if the prefix is invalid,
+		// so lenient parsing can continue after it.
+		var invalid error
 		bt, err := ParseMac(s)
 		if err != nil {
-			return generated, err
+			invalid = err
+			bt = &HardwareAddr{}
 		}
 
-		e := Entry{Prefix: *bt, Manufacturer: arr[len(arr)-1]}
-		for scanner.Scan() {
+		// In oui.txt the name is everything after the marker, also if it contains tabs.
+		// In the manuf format the last column is the long name.
+		name := arr[len(arr)-1]
+		if ouiMarkers.MatchString(arr[0]) {
+			name = strings.Join(arr[1:], " ")
+		}
+		// Collapse runs of whitespace in the name, so search and comparisons work.
+		e := Entry{Prefix: *bt, Manufacturer: strings.Join(strings.Fields(name), " ")}
+		// Wireshark style mask, for instance "00:55:DA:A0:00:00/28"
+		if mask := matches[0][2]; mask != "" && invalid == nil {
+			p, err := ParsePrefix(s + "/" + mask)
+			if err != nil {
+				invalid = err
+			} else {
+				e.PrefixLen = p.Bits
+				e.Extension = p.Extension()
+			}
+		}
+		truncated := false
+		for {
+			lineStart := pos
+			if !scanner.Scan() {
+				break
+			}
 			text := scanner.Text()
 			if len(text) < 2 {
 				break
 			}
 			if text[0] != '\t' {
+				head := strings.SplitN(text, "\t", 2)[0]
+				if prefixRe.MatchString(head) {
+					pending, next = true, lineStart
+					break
+				}
+				if m := rangeRe.FindStringSubmatch(head); m != nil && e.PrefixLen == 0 && invalid == nil {
+					invalid = setRange(&e, m[1], m[2])
+				}
 				continue
 			}
-			e.Address = append(e.Address, strings.Trim(text, "\t \r\n"))
+			addr := strings.Trim(text, "\t \r\n")
+			// The country is the last line, also when lines are skipped.
+			e.Country = addr
+			if maxAddress > 0 && len(e.Address) >= maxAddress {
+				truncated = true
+				continue
+			}
+			e.Address = append(e.Address, addr)
 		}
-		if len(e.Address) > 0 {
-			e.Country = e.Address[len(e.Address)-1]
+		end := pos
+		if pending {
+			end = next
 		}
-
-		i := int(bt[0])<<16 | int(bt[1])<<8 | int(bt[2])
-		if i&local != 0 {
-			e.Local = true
+		if invalid != nil {
+			if !o.lenient {
+				return generated, invalid
+			}
+			o.warn(report, ErrInvalidRecord{Line: line, Reason: invalid.Error()})
+			continue
 		}
-		if i&multicast != 0 {
-			e.Multicast = true
+		if truncated {
+			report.TruncatedAddresses++
+		}
+		setFlags(&e)
+		report.Records++
+		report.FooterBytes = 0
+		if err := fn(e, start, end-start); err != nil {
+			return generated, err
 		}
-		db[*bt] = e
 	}
-	return generated, nil
+	return generated, scanner.Err()
+}
+
+// The prefix starting a record, like "00-22-72", with an optional length like "/36".
+var prefixRe = regexp.MustCompile(`((?:(?:[0-9a-fA-F]{2})[-:]){2,5}(?:[0-9a-fA-F]{2}))(?:/(\w{1,2}))?`)
+
+// Buffers for the lines read by scanRecords, which is called
+// for every lookup in databases reading entries from a file.
+var scanBuffers = sync.Pool{New: func() any {
+	b := make([]byte, 0, 4096)
+	return &b
+}}
+
+// The range of an MA-M or MA-S assignment within the OUI, like "F00000-F00FFF".
+var rangeRe = regexp.MustCompile(`^([0-9A-Fa-f]{6})-([0-9A-Fa-f]{6})\b`)
+
+// Set the extension and prefix length of an entry from the range of its assignment.
+// The range must be a block of a power of two addresses, starting at a multiple of its size.
+func setRange(e *Entry, start, end string) error {
+	s, _ := strconv.ParseUint(start, 16, 32)
+	n, _ := strconv.ParseUint(end, 16, 32)
+	size := n - s + 1
+	if n < s || size&(size-1) != 0 || s&(size-1) != 0 {
+		return fmt.Errorf("range %s-%s is not an aligned block", start, end)
+	}
+	if prefixLen := 49 - bits.Len64(size); prefixLen != 24 {
+		e.PrefixLen = prefixLen
+		e.Extension = HardwareAddr{byte(s >> 16), byte(s >> 8), byte(s)}
+	}
+	return nil
+}
+
+// Set the flags of an entry from the prefix and manufacturer.
+func setFlags(e *Entry) {
+	i := int(e.Prefix[0])<<16 | int(e.Prefix[1])<<8 | int(e.Prefix[2])
+	if i&local != 0 {
+		e.Local = true
+	}
+	if i&multicast != 0 {
+		e.Multicast = true
+	}
+	if strings.EqualFold(strings.TrimSpace(e.Manufacturer), private) {
+		e.IsPrivate = true
+	}
 }
 
 const local = 0x020000
 const multicast = 0x010000
 
+// The organization listed for private registrations.
+const private = "PRIVATE"
+
 // OpenStatic will read the content of the given reader and return a database with the content.
 // You will not be able to update this database, but you can request the raw database
 // with the RawDB() function.
-func OpenStatic(in io.Reader) (StaticDB, error) {
-	dst := make(map[[3]byte]Entry)
-	db := newStatic(dst)
-	t, err := scanOUI(in, ouiDB(dst))
+func OpenStatic(in io.Reader, opts ...Option) (StaticDB, error) {
+	o := newOptions(opts)
+	dst := o.newDB()
+	t, dups, err := load(in, dst, o)
+	db := newStatic(dst, dups, o)
 	db.generatedAt(t)
 	return db, err
 }
@@ -333,15 +1042,16 @@ func OpenStatic(in io.Reader) (StaticDB, error) {
 // OpenStaticFile will read the content of a oui.txt file and return a database with the content.
 // You will not be able to update this database, but you can request the raw database
 // with the RawDB() function.
-func OpenStaticFile(name string) (StaticDB, error) {
-	dst := make(map[[3]byte]Entry)
+func OpenStaticFile(name string, opts ...Option) (StaticDB, error) {
 	file, err := os.Open(name)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
-	db := newStatic(dst)
-	t, err := scanOUI(file, ouiDB(dst))
+	o := newOptions(opts)
+	dst := o.newDB()
+	t, dups, err := load(file, dst, o)
+	db := newStatic(dst, dups, o)
 	db.generatedAt(t)
 	return db, err
 }
@@ -350,41 +1060,126 @@ func OpenStaticFile(name string) (StaticDB, error) {
 // and return a database with the content.
 // You will not be able to update this database, but you can request the raw database
 // with the RawDB() function.
-func OpenStaticHttp(url string) (StaticDB, error) {
-	dst := make(ouiDB)
+func OpenStaticHttp(url string, opts ...Option) (StaticDB, error) {
 	resp, err := http.Get(url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	db := newStatic(dst)
-	t, err := scanOUI(resp.Body, dst)
+	o := newOptions(opts)
+	dst := o.newDB()
+	t, dups, err := load(resp.Body, dst, o)
+	db := newStatic(dst, dups, o)
 	db.generatedAt(t)
 	return db, err
 }
 
 // Open will read the content of the given reader and return a database with the content.
+// The "(hex)" and "(base 16)" markers of oui.txt files are not case sensitive.
 // You can update the returned database using the Update/UpdateFile/UpdateHttp functions.
-func Open(in io.Reader) (DynamicDB, error) {
-	dst := make(map[[3]byte]Entry)
-	db := newDynamic(dst)
-	t, err := scanOUI(in, ouiDB(dst))
+// The options given are also used when the database is updated.
+func Open(in io.Reader, opts ...Option) (DynamicDB, error) {
+	o := newOptions(opts)
+	dst := o.newDB()
+	t, dups, err := load(in, dst, o)
+	db := newDynamic(dst, dups, o)
 	db.generatedAt(t)
 	return db, err
 }
 
+// MustOpen will read the content of the given reader like Open,
+// and panic if it cannot be read.
+// It is intended for package initialization and tests using a known good database,
+// and should not be used where an error can be handled.
+func MustOpen(in io.Reader, opts ...Option) DynamicDB {
+	db, err := Open(in, opts...)
+	if err != nil {
+		panic("oui: MustOpen: " + err.Error())
+	}
+	return db
+}
+
 // OpenFile will read the content of a oui.txt file and return a database with the content.
+// Files with a ".jsonl" or ".ndjson" extension are read with OpenJSONLines.
+// For other files the format is detected with DetectFormat,
+// and gzip compressed files are decompressed.
 // You can update the returned database using the Update/UpdateFile/UpdateHttp functions.
-func OpenFile(name string) (DynamicDB, error) {
-	dst := make(map[[3]byte]Entry)
+// The options given are also used when the database is updated.
+func OpenFile(name string, opts ...Option) (DynamicDB, error) {
 	file, err := os.Open(name)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
-	db := newDynamic(dst)
-	t, err := scanOUI(file, ouiDB(dst))
+	return openNamed(file, name, opts)
+}
+
+// OpenFS will read the content of the named file in fsys and return a database with the content.
+// The format is chosen like OpenFile does.
+// You can update the returned database using the Update/UpdateFile/UpdateHttp functions.
+// The options given are also used when the database is updated.
+func OpenFS(fsys fs.FS, name string, opts ...Option) (DynamicDB, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return openNamed(file, name, opts)
+}
+
+// OpenBytes will read the content of b and return a database with the content.
+// The format is detected with DetectFormat, so gzip compressed content
+// and JSON lines are also read. Content that isn't recognized is read as oui.txt.
+// b is not modified, and can be reused once OpenBytes returns.
+// You can update the returned database using the Update/UpdateFile/UpdateHttp functions.
+// The options given are also used when the database is updated.
+func OpenBytes(b []byte, opts ...Option) (DynamicDB, error) {
+	return openDetected(bytes.NewReader(b), opts)
+}
+
+// OpenStaticBytes will index the oui.txt content of b, and return a database
+// that reads entries from b when they are looked up, like OpenStaticReaderAt.
+// The content is not copied, so b must not be modified while the database is used.
+func OpenStaticBytes(b []byte) (StaticDB, error) {
+	return OpenStaticReaderAt(bytes.NewReader(b), int64(len(b)))
+}
+
+// Read the content of a file, choosing the format by the extension of the name.
+func openNamed(in io.Reader, name string, opts []Option) (DynamicDB, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jsonl", ".ndjson":
+		return OpenJSONLines(in, opts...)
+	}
+	return openDetected(in, opts)
+}
+
+// Read the content, choosing the format with DetectFormat.
+// Content that isn't recognized is read as oui.txt.
+func openDetected(in io.Reader, opts []Option) (DynamicDB, error) {
+	f, in, err := DetectFormat(in)
+	if err != nil {
+		return nil, err
+	}
+	switch f {
+	case FormatJSONLines:
+		return OpenJSONLines(in, opts...)
+	case FormatGzip:
+		zr, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return openDetected(zr, opts)
+	case FormatCSV:
+		return OpenCSV(in, opts...)
+	case FormatSQLite:
+		return nil, ErrUnsupportedFormat
+	}
+	o := newOptions(opts)
+	dst := o.newDB()
+	t, dups, err := load(in, dst, o)
+	db := newDynamic(dst, dups, o)
 	db.generatedAt(t)
 	return db, err
 }
@@ -392,36 +1187,66 @@ func OpenFile(name string) (DynamicDB, error) {
 // OpenHttp will request the content of the URL given, parse it as a oui.txt file
 // and return a database with the content.
 // You can update the returned database using the Update/UpdateFile/UpdateHttp functions.
-func OpenHttp(url string) (DynamicDB, error) {
-	dst := make(ouiDB)
+// The options given are also used when the database is updated.
+func OpenHttp(url string, opts ...Option) (DynamicDB, error) {
 	resp, err := http.Get(url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	db := newDynamic(dst)
-	t, err := scanOUI(resp.Body, dst)
+	o := newOptions(opts)
+	dst := o.newDB()
+	t, dups, err := load(resp.Body, dst, o)
+	db := newDynamic(dst, dups, o)
 	db.generatedAt(t)
 	return db, err
 }
 
 // Update will read and replace the content of the database.
+// The content is read in the format the database was opened from,
+// so a database from OpenCSV is updated with CSV content.
 // The database will remain usable while the update/parsing
 // is taking place.
 // If an error occurs during read or parsing, the database will not be replaced
 // and the previous version will continue to be served.
+// The content is replaced at once when r has been read to the end,
+// so r can be a stream like os.Stdin, and input that fails to be read
+// before the end leaves the database untouched.
+// Use UpdateWithResult to also get the number of changed entries.
 func Update(db DynamicDB, r io.Reader) error {
-	dst := make(ouiDB)
-	t, err := scanOUI(r, dst)
+	o := db.options()
+	dst := o.newDB()
+	t, dups, err := load(r, dst, o)
 	if err != nil {
 		return err
 	}
-	db.updateDb(dst, t)
-	return nil
+	return db.updateDb(dst, dups, t)
+}
+
+// UpdateWithResult will read and replace the content of the database like Update,
+// and return the number of entries that were added, removed and changed
+// compared to the previous content.
+// If an error occurs, the database will not be replaced and an empty result is returned.
+func UpdateWithResult(db DynamicDB, r io.Reader) (UpdateResult, error) {
+	o := db.options()
+	dst := o.newDB()
+	t, dups, err := load(r, dst, o)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	res, err := updateResult(db, dst)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	if err := db.updateDb(dst, dups, t); err != nil {
+		return UpdateResult{}, err
+	}
+	return res, nil
 }
 
 // UpdateFile will read a file and replace the content of the database.
+// The file is read in the format the database was opened from, like Update.
 // The database will remain usable while the update/parsing
 // is taking place.
 // If an error occurs during read or parsing, the database will not be replaced
@@ -433,16 +1258,17 @@ func UpdateFile(db DynamicDB, name string) error {
 	}
 	defer file.Close()
 
-	dst := make(ouiDB)
-	t, err := scanOUI(file, dst)
+	o := db.options()
+	dst := o.newDB()
+	t, dups, err := load(file, dst, o)
 	if err != nil {
 		return err
 	}
-	db.updateDb(dst, t)
-	return nil
+	return db.updateDb(dst, dups, t)
 }
 
 // UpdateHttp will download from a URL and replace the content of the database.
+// The content is read in the format the database was opened from, like Update.
 // The database will remain usable while the updating/parsing
 // is taking place.
 // If an error occurs during read or parsing, the database will not be replaced
@@ -454,13 +1280,13 @@ func UpdateHttp(db DynamicDB, url string) error {
 	}
 	defer resp.Body.Close()
 
-	dst := make(ouiDB)
-	t, err := scanOUI(resp.Body, dst)
+	o := db.options()
+	dst := o.newDB()
+	t, dups, err := load(resp.Body, dst, o)
 	if err != nil {
 		return err
 	}
-	db.updateDb(dst, t)
-	return nil
+	return db.updateDb(dst, dups, t)
 }
 
 // PrintDb the entire database to stdout.
@@ -0,0 +1,554 @@
+package oui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// MA-S records in the oui36.txt format, sharing the 70-B3-D5 OUI,
+// and the parent entry of the OUI in the oui.txt format.
+const masRecords = "70-B3-D5   (hex)\t\tFirst Sensors\r\n" +
+	"F00000-F00FFF     (base 16)\t\tFirst Sensors\r\n" +
+	"\t\t\t\tSpringfield  12345\r\n" +
+	"\t\t\t\tUS\r\n" +
+	"\r\n" +
+	"70-B3-D5   (hex)\t\tSecond Sensors\r\n" +
+	"5A0000-5A0FFF     (base 16)\t\tSecond Sensors\r\n" +
+	"\t\t\t\tShelbyville  54321\r\n" +
+	"\t\t\t\tUS\r\n" +
+	"\r\n"
+
+const parentRecord = "70-B3-D5   (hex)\t\tIEEE Registration Authority\r\n" +
+	"70B3D5     (base 16)\t\tIEEE Registration Authority\r\n" +
+	"\t\t\t\t445 Hoes Lane\r\n" +
+	"\t\t\t\tPiscataway  NJ  08554\r\n" +
+	"\t\t\t\tUS\r\n" +
+	"\r\n"
+
+func TestOpenRanges(t *testing.T) {
+	db, err := Open(strings.NewReader(parentRecord + masRecords))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", db.Len())
+	}
+	e, err := db.LookUpUint64(0x70b3d5f00123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := HardwareAddr{0xf0, 0x00, 0x00}
+	if e.Manufacturer != "First Sensors" || e.PrefixLen != 36 || e.Extension != want {
+		t.Errorf("LookUpUint64 = %q/%d extension %s, want First Sensors/36 extension %s", e.Manufacturer, e.PrefixLen, e.Extension, want)
+	}
+	if e.Confidence != ConfidenceHigh || e.Registry() != RegistryMAS {
+		t.Errorf("LookUpUint64 confidence %s registry %s, want high and %s", e.Confidence, e.Registry(), RegistryMAS)
+	}
+	e, err = db.Query("70:b3:d5:5a:01:02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != "Second Sensors" {
+		t.Errorf("Query = %q, want Second Sensors", e.Manufacturer)
+	}
+
+	// Only the OUI is known, so the parent is returned.
+	e, err = db.LookUp(HardwareAddr{0x70, 0xb3, 0xd5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != registrationAuthority || e.Confidence != ConfidenceLow {
+		t.Errorf("LookUp = %q with %s confidence, want the parent with low confidence", e.Manufacturer, e.Confidence)
+	}
+	// Addresses outside the loaded assignments are in the parent.
+	e, err = db.LookUpUint64(0x70b3d5123456)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != registrationAuthority || e.Confidence != ConfidenceLow {
+		t.Errorf("LookUpUint64 outside the assignments = %q with %s confidence", e.Manufacturer, e.Confidence)
+	}
+
+	c, err := db.LookUpCandidates(HardwareAddr{0x70, 0xb3, 0xd5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range c {
+		names = append(names, e.Manufacturer)
+	}
+	if got, want := strings.Join(names, ","), "Second Sensors,First Sensors,"+registrationAuthority; got != want {
+		t.Errorf("LookUpCandidates = %s, want %s", got, want)
+	}
+
+	// Exporting in the oui.txt format writes the ranges again.
+	var buf bytes.Buffer
+	if err := ExportFiltered(db, &buf, nil, FormatOUI); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "F00000-F00FFF     (base 16)") {
+		t.Errorf("export has no range:\n%s", buf.String())
+	}
+	again, err := Open(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(db, again) {
+		t.Error("exported database is not equal to the original")
+	}
+	buf.Reset()
+	if err := ExportFiltered(db, &buf, nil, FormatManuf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "70:B3:D5:F0:00:00/36\tFirst Sensors") {
+		t.Errorf("manuf export has no mask:\n%s", buf.String())
+	}
+}
+
+func TestOpenInvalidRange(t *testing.T) {
+	bad := strings.Replace(masRecords, "F00000-F00FFF", "F00000-F00FFE", 1)
+	if _, err := Open(strings.NewReader(bad)); err == nil {
+		t.Error("no error for a range that isn't an aligned block")
+	}
+	var report ParseReport
+	db, err := Open(strings.NewReader(bad), WithLenientParsing(), WithParseReport(&report))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 1 || len(report.Warnings) != 1 {
+		t.Errorf("lenient parsing kept %d entries with %d warnings, want 1 and 1", db.Len(), len(report.Warnings))
+	}
+	var invalid ErrInvalidRecord
+	if !errors.As(report.Warnings[0], &invalid) || invalid.Line != 1 {
+		t.Errorf("warning = %v, want an invalid record on line 1", report.Warnings[0])
+	}
+}
+
+func TestOUI36(t *testing.T) {
+	const mac = 0x70b3d5f57abc
+	h := OUI36(mac)
+	if got, want := h.String(), "70:b3:d5:f5:7"; got != want {
+		t.Errorf("String = %s, want %s", got, want)
+	}
+	if h.OUI() != OUI24(mac) {
+		t.Errorf("OUI = %s, want %s", h.OUI(), OUI24(mac))
+	}
+	if got, want := h.Prefix().String(), "70:b3:d5:f5:70:00/36"; got != want {
+		t.Errorf("Prefix = %s, want %s", got, want)
+	}
+	if !h.Prefix().contains(prefixUint64(mac)) {
+		t.Errorf("%s does not contain %012x", h.Prefix(), uint64(mac))
+	}
+}
+
+func BenchmarkLookUpUint64(b *testing.B) {
+	db := openRegistries(b, "oui.txt", "mam.txt", "oui36.txt")
+	macs := []uint64{0x70b3d50e0123, 0x70b3d5f57abc, 0x0055daa12345, 0x002272000001}
+	b.Run("uint64", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			db.LookUpUint64(macs[i%len(macs)])
+		}
+	})
+	b.Run("HardwareAddr", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			db.LookUp(OUI24(macs[i%len(macs)]))
+		}
+	})
+}
+
+func TestUpdateFormat(t *testing.T) {
+	src, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		format Format
+		open   func(r io.Reader, opts ...Option) (DynamicDB, error)
+	}{
+		{format: FormatCSV, open: OpenCSV},
+		{format: FormatJSONLines, open: OpenJSONLines},
+	} {
+		var buf bytes.Buffer
+		if err := ExportFiltered(src, &buf, nil, test.format); err != nil {
+			t.Fatal(err)
+		}
+		content := buf.String()
+		db, err := test.open(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("%s: %v", test.format, err)
+		}
+		if err := Update(db, strings.NewReader(content)); err != nil {
+			t.Fatalf("%s: Update: %v", test.format, err)
+		}
+		if db.Len() != src.Len() {
+			t.Errorf("%s: Len after Update = %d, want %d", test.format, db.Len(), src.Len())
+		}
+		e, err := db.LookUp(HardwareAddr{0x00, 0x22, 0x72})
+		if err != nil {
+			t.Fatalf("%s: %v", test.format, err)
+		}
+		if e.Manufacturer != "American Micro-Fuel Device Corp." {
+			t.Errorf("%s: LookUp after Update = %q", test.format, e.Manufacturer)
+		}
+	}
+}
+
+func TestOpenJSONLinesOptions(t *testing.T) {
+	src := openRegistries(t, "oui.txt", "oui36.txt")
+	var buf bytes.Buffer
+	if err := WriteJSONLines(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+	content := buf.String()
+	db, err := OpenJSONLines(strings.NewReader(content), WithRegistryFilter(RegistryMAS), WithSource("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 3 {
+		t.Errorf("Len with a registry filter = %d, want 3", db.Len())
+	}
+	e, err := db.LookUpUint64(0x70b3d5f57abc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Source != "test" {
+		t.Errorf("Source = %q, want test", e.Source)
+	}
+	db, err = OpenJSONLines(strings.NewReader(content), WithMaxEntries(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 2 {
+		t.Errorf("Len with a limit = %d, want 2", db.Len())
+	}
+	// The same entry twice is kept as a duplicate.
+	line := content[:strings.IndexByte(content, '\n')+1]
+	db, err = OpenJSONLines(strings.NewReader(line+line), WithKeepDuplicates())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := decodeJSONEntry([]byte(line))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := db.LookUpCandidates(first.Prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 1 || len(c) != 2 {
+		t.Errorf("Len = %d with %d candidates, want 1 with 2", db.Len(), len(c))
+	}
+}
+
+func FuzzOpen(f *testing.F) {
+	f.Add([]byte(parentRecord + masRecords))
+	f.Add([]byte("00-22-72   (hex)\t\tAmerican Micro-Fuel Device Corp.\r\n002272     (base 16)\t\tAmerican Micro-Fuel Device Corp.\r\n\r\n"))
+	f.Add([]byte("00:55:DA:A0:00:00/28\tSpeechlab\n"))
+	f.Add([]byte("Registry,Assignment,Organization Name,Organization Address\nMA-L,002272,Test,\"Line 1\nUS\"\n"))
+	f.Add([]byte(`{"prefix":"00:22:72","manufacturer":"Test"}` + "\n"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		db, err := OpenBytes(b, WithLenientParsing())
+		if err != nil {
+			return
+		}
+		n := 0
+		db.Iterate(func(e *Entry) bool {
+			n++
+			// The first address of the assignment is found in it, or in a longer one.
+			a := e.Assignment()
+			q := net.HardwareAddr(a.Addr[:]).String()
+			got, err := db.Query(q)
+			if err != nil || got.bits() < a.Bits || !got.Assignment().contains(prefixUint64(prefixKey(a))) {
+				t.Fatalf("Query(%s) = %v, %v, want the entry for %s or a longer one", q, got, err, a)
+			}
+			return true
+		})
+		if n != db.Len() {
+			t.Fatalf("iterated %d entries, Len = %d", n, db.Len())
+		}
+	})
+}
+
+func TestPrivate(t *testing.T) {
+	// A private registration as listed in oui.txt, without an address.
+	const record = "A4-DA-22   (hex)\t\tPRIVATE\r\n" +
+		"A4DA22     (base 16)\t\tPRIVATE\r\n" +
+		"\r\n"
+	db, err := Open(strings.NewReader(record + parentRecord))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := db.LookUp(HardwareAddr{0xa4, 0xda, 0x22})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.IsPrivate || e.Manufacturer != "PRIVATE" || len(e.Address) != 0 {
+		t.Errorf("LookUp = %q private %v with %d address lines, want a private entry", e.Manufacturer, e.IsPrivate, len(e.Address))
+	}
+	e, err = db.LookUp(HardwareAddr{0x70, 0xb3, 0xd5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.IsPrivate {
+		t.Errorf("%q is private", e.Manufacturer)
+	}
+}
+
+func TestOpenFS(t *testing.T) {
+	b, err := os.ReadFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"data/oui.txt":   {Data: b},
+		"data/oui.jsonl": {Data: []byte(`{"prefix":"00:22:72","manufacturer":"From JSON"}` + "\n")},
+	}
+	db, err := OpenFS(fsys, "data/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(db, want) {
+		t.Error("database from OpenFS is not equal to the one from OpenFile")
+	}
+	// The format is chosen by the extension.
+	db, err = OpenFS(fsys, "data/oui.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := db.LookUp(HardwareAddr{0x00, 0x22, 0x72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != "From JSON" {
+		t.Errorf("LookUp = %q, want From JSON", e.Manufacturer)
+	}
+	if _, err := OpenFS(fsys, "data/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("opening a missing file: %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestOpenFooter(t *testing.T) {
+	b, err := os.ReadFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want ParseReport
+	db, err := OpenBytes(b, WithParseReport(&want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const footer = "Copyright (c) IEEE. All rights reserved.\r\n" +
+		"This file is provided as is.\r\n"
+	var report ParseReport
+	withFooter, err := OpenBytes(append(b, footer...), WithParseReport(&report))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(db, withFooter) {
+		t.Error("the footer changed the entries")
+	}
+	if report.Records != want.Records || report.IgnoredLines != want.IgnoredLines+2 {
+		t.Errorf("%d records and %d ignored lines, want %d and %d", report.Records, report.IgnoredLines, want.Records, want.IgnoredLines+2)
+	}
+	if report.FooterBytes != int64(len(footer)) {
+		t.Errorf("FooterBytes = %d, want %d", report.FooterBytes, len(footer))
+	}
+}
+
+func TestOpenManufacturerWhitespace(t *testing.T) {
+	const record = "00-22-72   (hex)\t\tAmerican  Micro-Fuel\tDevice Corp.\t\t \r\n" +
+		"002272     (base 16)\t\tAmerican  Micro-Fuel\tDevice Corp.\t\t \r\n" +
+		"\t\t\t\t2181 Buchanan Loop\r\n" +
+		"\t\t\t\tUS\r\n" +
+		"\r\n"
+	db, err := Open(strings.NewReader(record), WithSourceLines())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := db.LookUp(HardwareAddr{0x00, 0x22, 0x72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "American Micro-Fuel Device Corp."; e.Manufacturer != want {
+		t.Errorf("Manufacturer = %q, want %q", e.Manufacturer, want)
+	}
+	// The raw text is still available.
+	if !strings.Contains(e.SourceLine(), "Device Corp.\t\t \r\n") {
+		t.Errorf("SourceLine = %q, want the raw record", e.SourceLine())
+	}
+}
+
+func TestResetUpdate(t *testing.T) {
+	db, err := OpenFile("testdata/oui.txt", WithSource("mirror"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := db.Len()
+	db.SetNameNormalizer(strings.ToLower)
+	hw := HardwareAddr{0x00, 0x22, 0x72}
+
+	// Readers may run while the database is reset and reloaded.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			if e, err := db.LookUp(hw); err == nil && e.Manufacturer != "American Micro-Fuel Device Corp." {
+				t.Errorf("LookUp during reset = %q", e.Manufacturer)
+				return
+			}
+		}
+	}()
+	db.Reset()
+	if db.Len() != 0 {
+		t.Errorf("Len after Reset = %d, want 0", db.Len())
+	}
+	if _, err := db.LookUp(hw); err == nil {
+		t.Error("LookUp found an entry after Reset")
+	}
+	if !db.Generated().IsZero() || !db.LoadedAt().IsZero() {
+		t.Errorf("times after Reset = %v, %v, want zero", db.Generated(), db.LoadedAt())
+	}
+
+	if err := UpdateFile(db, "testdata/oui.txt"); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if db.Len() != want {
+		t.Errorf("Len after Update = %d, want %d", db.Len(), want)
+	}
+	if db.LoadedAt().IsZero() {
+		t.Error("LoadedAt not set by Update")
+	}
+	e, err := db.LookUp(hw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != "American Micro-Fuel Device Corp." || e.Source != "mirror" {
+		t.Errorf("LookUp after Update = %q from %q", e.Manufacturer, e.Source)
+	}
+	// The normalizer is kept, so the entries compare case-insensitively.
+	a, b := HardwareAddr{0x02, 0x00, 0x01}, HardwareAddr{0x02, 0x00, 0x02}
+	db.UpdateEntry(a, Entry{Prefix: a, Manufacturer: "Acme"})
+	db.UpdateEntry(b, Entry{Prefix: b, Manufacturer: "ACME"})
+	if same, err := SameVendor(db, a, b); err != nil || !same {
+		t.Errorf("SameVendor after Reset = %v, %v, want true", same, err)
+	}
+}
+
+func TestOpenMaxAddressLines(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("00-22-72   (hex)\t\tAmerican Micro-Fuel Device Corp.\r\n" +
+		"002272     (base 16)\t\tAmerican Micro-Fuel Device Corp.\r\n")
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(&b, "\t\t\t\t%d Buchanan Loop\r\n", i)
+	}
+	b.WriteString("\t\t\t\tUS\r\n\r\n" +
+		"00-60-92   (hex)\t\tMICRO/SYS, INC.\r\n" +
+		"006092     (base 16)\t\tMICRO/SYS, INC.\r\n" +
+		"\t\t\t\tUS\r\n\r\n")
+	for _, test := range []struct {
+		opts      []Option
+		lines     int
+		truncated int
+	}{
+		{lines: DefaultMaxAddressLines, truncated: 1},
+		{opts: []Option{WithMaxAddressLines(3)}, lines: 3, truncated: 1},
+		{opts: []Option{WithMaxAddressLines(0)}, lines: 100001},
+	} {
+		var report ParseReport
+		db, err := Open(strings.NewReader(b.String()), append(test.opts, WithParseReport(&report))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := db.LookUp(HardwareAddr{0x00, 0x22, 0x72})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(e.Address) != test.lines || e.Address[0] != "0 Buchanan Loop" {
+			t.Errorf("%d address lines starting with %q, want %d", len(e.Address), e.Address[0], test.lines)
+		}
+		if e.Country != "US" {
+			t.Errorf("Country = %q, want the last line", e.Country)
+		}
+		if report.TruncatedAddresses != test.truncated || report.Records != 2 {
+			t.Errorf("%d records and %d truncated, want 2 and %d", report.Records, report.TruncatedAddresses, test.truncated)
+		}
+		// The following record is read as usual.
+		if _, err := db.LookUp(HardwareAddr{0x00, 0x60, 0x92}); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestOpenMarkerCase(t *testing.T) {
+	for _, name := range []string{"testdata/oui.txt", "testdata/mam.txt", "testdata/oui36.txt"} {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db, err := OpenBytes(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range []*strings.Replacer{
+			strings.NewReplacer("(hex)", "(HEX)", "(base 16)", "(BASE 16)"),
+			strings.NewReplacer("(hex)", "(Hex)", "(base 16)", "(Base 16)"),
+		} {
+			s := r.Replace(string(b))
+			var report ParseReport
+			other, err := Open(strings.NewReader(strings.ToLower(s)), WithParseReport(&report))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Records == 0 || other.Len() != db.Len() {
+				t.Errorf("%s: %d entries with lowercase content, want %d", name, other.Len(), db.Len())
+			}
+			other, err = Open(strings.NewReader(s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(db, other) {
+				t.Errorf("%s: markers like %q changed the entries", name, r.Replace("(hex)"))
+			}
+		}
+	}
+}
+
+func TestEntryJSONRegistered(t *testing.T) {
+	e := Entry{Prefix: HardwareAddr{0x00, 0x22, 0x72}, Manufacturer: "American Micro-Fuel Device Corp."}
+	b, err := json.Marshal(&e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("registered")) {
+		t.Errorf("zero Registered is encoded: %s", b)
+	}
+	e.Registered = time.Date(2008, 7, 1, 0, 0, 0, 0, time.UTC)
+	b, err = json.Marshal(&e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Entry
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Registered.Equal(e.Registered) {
+		t.Errorf("Registered = %v after decoding %s, want %v", got.Registered, b, e.Registered)
+	}
+}
@@ -2,18 +2,20 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"github.com/gorhill/cronexpr"
 	"github.com/klauspost/oui"
 	"log"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
 	"runtime"
 	"strings"
 	"time"
 )
 
-var ouiFile = flag.String("open", "oui.txt", "File name with oui.txt to open. Set to 'http' to download")
+var ouiFile = flag.String("open", "oui.txt", "File name with oui.txt to open. Set to 'http' to download, or '-' to read from stdin")
 var listen = flag.String("listen", ":5000", "Listen address and port, for instance 127.0.0.1:5000")
 var threads = flag.Int("threads", runtime.NumCPU(), "Number of threads to use. Defaults to number of detected cores")
 var pretty = flag.Bool("pretty", false, "Should output be formatted with newlines and intentation")
@@ -42,10 +44,20 @@ func main() {
 	fileName := ""
 	var err error
 
-	if strings.HasPrefix(*ouiFile, "http") {
+	if *ouiFile == "-" {
+		// Stdin can only be read once.
+		if cron != nil {
+			log.Fatal("Cannot update a database read from stdin")
+		}
+		log.Println("Reading database from stdin")
+		db, err = oui.Open(os.Stdin)
+		if err != nil {
+			log.Fatalf("Error reading stdin:%s", err.Error())
+		}
+	} else if strings.HasPrefix(*ouiFile, "http") {
 		url = *ouiFile
 		if url == "http" {
-			url = "http://standards-oui.ieee.org/oui.txt"
+			url = oui.RegistryURLs[oui.RegistryMAL]
 		}
 		log.Println("Downloading new Db from: " + url)
 		db, err = oui.OpenHttp(url)
@@ -136,7 +148,7 @@ func main() {
 
 		entry, err := db.LookUp(*hw)
 		if err != nil {
-			if err == oui.ErrNotFound {
+			if errors.Is(err, oui.ErrNotFound) {
 				res.Error = "not found in db"
 				w.WriteHeader(http.StatusNotFound)
 				return
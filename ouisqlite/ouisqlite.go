@@ -0,0 +1,207 @@
+// SQLite backed OUI database
+//
+// This package stores an OUI database in a SQLite file, so lookups
+// read from disk and memory usage stays bounded regardless of the
+// size of the database.
+// It uses cgo, which is why it is kept out of the oui package.
+package ouisqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/oui"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `
+CREATE TABLE entries (
+	prefix          INTEGER NOT NULL,
+	extension       INTEGER NOT NULL,
+	manufacturer    TEXT NOT NULL,
+	address         TEXT NOT NULL,
+	country         TEXT NOT NULL,
+	prefix_len      INTEGER NOT NULL,
+	local           BOOLEAN NOT NULL,
+	multicast       BOOLEAN NOT NULL,
+	private         BOOLEAN NOT NULL,
+	source          TEXT NOT NULL,
+	registered      TEXT NOT NULL,
+	tags            TEXT NOT NULL,
+	registration_id TEXT NOT NULL,
+	PRIMARY KEY (prefix, extension, prefix_len)
+);
+CREATE TABLE meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// Address lines and tags are stored joined by newlines.
+const addressSeparator = "\n"
+
+// DB is a read only database backed by a SQLite file.
+// It is safe for concurrent use.
+type DB struct {
+	db     *sql.DB
+	dbTime time.Time
+}
+
+// Check we implement the interfaces we promise
+var _ oui.ReadOnlyDB = &DB{}
+
+// Open will open a SQLite file written by BuildSQLite.
+func Open(path string) (*DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	sdb, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	db := &DB{db: sdb}
+	var generated string
+	err = sdb.QueryRow(`SELECT value FROM meta WHERE key = 'generated'`).Scan(&generated)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		sdb.Close()
+		return nil, err
+	default:
+		// We ignore the error, like the oui.txt parser.
+		db.dbTime, _ = time.Parse(time.RFC3339Nano, generated)
+	}
+	return db, nil
+}
+
+// BuildSQLite will write all entries of db to a new SQLite file at path.
+// All exported fields of the entries are stored, except Confidence and
+// ParentOrganization, which are set by lookups.
+// An existing file will be replaced.
+func BuildSQLite(db oui.DynamicDB, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	sdb, err := sql.Open("sqlite3", "file:"+path)
+	if err != nil {
+		return err
+	}
+	defer sdb.Close()
+	if _, err := sdb.Exec(schema); err != nil {
+		return err
+	}
+	tx, err := sdb.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT INTO entries VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	var insertErr error
+	err = db.Iterate(func(e *oui.Entry) bool {
+		var registered string
+		if !e.Registered.IsZero() {
+			registered = e.Registered.Format(time.RFC3339Nano)
+		}
+		_, insertErr = stmt.Exec(key(e.Prefix), key(e.Extension), e.Manufacturer, strings.Join(e.AddressLines(), addressSeparator),
+			e.Country, e.PrefixLen, e.Local, e.Multicast, e.IsPrivate,
+			e.Source, registered, strings.Join(e.Tags, addressSeparator), e.RegistrationID)
+		return insertErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	if insertErr != nil {
+		return insertErr
+	}
+	if t := db.Generated(); !t.IsZero() {
+		_, err := tx.Exec(`INSERT INTO meta VALUES ('generated', ?)`, t.Format(time.RFC3339Nano))
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// The table key of a prefix, also used for the extension.
+func key(hw oui.HardwareAddr) int64 {
+	return int64(hw[0])<<16 | int64(hw[1])<<8 | int64(hw[2])
+}
+
+// Query the database for an entry based on the mac address.
+// If more than 3 octets are given, MA-M and MA-S entries containing
+// the address are found, like oui.OuiDB.Query.
+// If none are found a oui.NotFoundError will be returned.
+func (db *DB) Query(mac string) (*oui.Entry, error) {
+	p, err := oui.ParsePrefix(mac)
+	if err != nil {
+		return nil, err
+	}
+	if p.Bits <= 24 {
+		return db.LookUp(p.OUI())
+	}
+	return db.lookUp(context.Background(), p.OUI(), key(p.Extension()), true)
+}
+
+// LookUp a hardware address and return the entry if any are found.
+// Only entries assigned 24 bits or less are returned.
+// If none are found a oui.NotFoundError will be returned.
+func (db *DB) LookUp(hw oui.HardwareAddr) (*oui.Entry, error) {
+	return db.LookUpContext(context.Background(), hw)
+}
+
+// LookUpContext will look up a hardware address like LookUp.
+// The query is cancelled if ctx is done before it completes.
+func (db *DB) LookUpContext(ctx context.Context, hw oui.HardwareAddr) (*oui.Entry, error) {
+	return db.lookUp(ctx, hw, 0, false)
+}
+
+// Look up the longest assignment of the OUI containing ext, the 24 bits after the OUI.
+// If full is false, only entries assigned 24 bits or less are considered.
+// Entries without a known prefix length are 24 bits.
+func (db *DB) lookUp(ctx context.Context, hw oui.HardwareAddr, ext int64, full bool) (*oui.Entry, error) {
+	e := oui.Entry{Prefix: hw}
+	var address, registered, tags string
+	var extension int64
+	err := db.db.QueryRowContext(ctx, `SELECT extension, manufacturer, address, country, prefix_len, local, multicast, private,
+		source, registered, tags, registration_id
+		FROM entries WHERE prefix = ? AND (prefix_len <= 24 OR (? AND extension = (? >> (48 - prefix_len)) << (48 - prefix_len)))
+		ORDER BY CASE prefix_len WHEN 0 THEN 24 ELSE prefix_len END DESC LIMIT 1`, key(hw), full, ext).Scan(
+		&extension, &e.Manufacturer, &address, &e.Country, &e.PrefixLen, &e.Local, &e.Multicast, &e.IsPrivate,
+		&e.Source, &registered, &tags, &e.RegistrationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, oui.NotFoundError{Addr: hw}
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Extension = oui.HardwareAddr{byte(extension >> 16), byte(extension >> 8), byte(extension)}
+	if address != "" {
+		e.Address = strings.Split(address, addressSeparator)
+	}
+	if tags != "" {
+		e.Tags = strings.Split(tags, addressSeparator)
+	}
+	if registered != "" {
+		// We ignore the error, like the generation time.
+		e.Registered, _ = time.Parse(time.RFC3339Nano, registered)
+	}
+	return &e, nil
+}
+
+// Get the generated time
+func (db *DB) Generated() time.Time {
+	return db.dbTime
+}
+
+// Close will close the SQLite file.
+func (db *DB) Close() error {
+	return db.db.Close()
+}
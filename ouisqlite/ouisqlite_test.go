@@ -0,0 +1,55 @@
+package ouisqlite
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/oui"
+)
+
+func TestBuildSQLite(t *testing.T) {
+	const entries = `{"prefix":"00:22:72","manufacturer":"American Micro-Fuel Device Corp.","address":["2181 Buchanan Loop","Ferndale  WA  98248","US"],` +
+		`"country":"US","source":"test","registered":"2012-03-04T05:06:07.5Z","tags":["IoT","Fuel"],"registration_id":"R-1"}` + "\n" +
+		`{"prefix":"70:b3:d5","extension":"f5:70:00","prefix_len":36,"manufacturer":"Aeronautics Ltd.","country":"IL"}` + "\n"
+	db, err := oui.OpenJSONLines(strings.NewReader(entries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.ApplyDelta(strings.NewReader("= 2026-10-14T07:00:00.25Z\n")); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "oui.sqlite")
+	if err := BuildSQLite(db, path); err != nil {
+		t.Fatal(err)
+	}
+	sdb, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sdb.Close()
+	if !sdb.Generated().Equal(db.Generated()) {
+		t.Errorf("Generated = %s, want %s", sdb.Generated(), db.Generated())
+	}
+	for _, mac := range []string{"00:22:72", "70:b3:d5:f5:7a:bc"} {
+		want, err := db.Query(mac)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := sdb.Query(mac)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Set by lookups, and not stored.
+		want.Confidence = 0
+		if !got.Registered.Equal(want.Registered) {
+			t.Errorf("%s: Registered = %s, want %s", mac, got.Registered, want.Registered)
+		}
+		got.Registered, want.Registered = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: read back %+v, want %+v", mac, got, want)
+		}
+	}
+}
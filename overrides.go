@@ -0,0 +1,77 @@
+package oui
+
+import (
+	"io"
+	"sort"
+)
+
+// OverrideConflict is an entry of the base registry
+// that was replaced or removed by an override.
+type OverrideConflict struct {
+	// The OUI of the assignment.
+	Prefix HardwareAddr
+	// The entry in the base registry.
+	Base *Entry
+	// The entry from the overrides, or nil if the entry was removed.
+	Override *Entry
+}
+
+// OpenWithOverrides will read a oui.txt file from base, and apply the
+// overrides before the database is returned, so local corrections
+// always win over the registry.
+// The overrides are read in the delta format described by ApplyDelta,
+// so entries can be added, changed and removed. Added and changed entries
+// are treated the same, and the generation time of the base is kept.
+// Base entries that were replaced with a different entry or removed are
+// returned as conflicts, sorted by assignment.
+// If the overrides cannot be read, no database is returned.
+// You can update the returned database using the Update/UpdateFile/UpdateHttp functions,
+// but the overrides are not applied again.
+func OpenWithOverrides(base, overrides io.Reader, opts ...Option) (DynamicDB, []OverrideConflict, error) {
+	d, err := readDelta(overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+	o := newOptions(opts)
+	dst := o.newDB()
+	t, dups, err := load(base, dst, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	conflicts := make(map[Prefix]OverrideConflict)
+	for _, k := range d.del {
+		if e, ok := dst[k]; ok {
+			conflicts[k] = OverrideConflict{Prefix: k.OUI(), Base: &e}
+		}
+		dst.del(k)
+		delete(dups, k)
+	}
+	for _, e := range d.set {
+		e := e
+		k := e.Assignment()
+		b, ok := dst[k]
+		// Compare with the base entry, also if it was removed above.
+		c, removed := conflicts[k]
+		if removed {
+			b, ok = *c.Base, true
+		}
+		switch {
+		case ok && !b.Equal(&e):
+			conflicts[k] = OverrideConflict{Prefix: e.Prefix, Base: &b, Override: &e}
+		case removed:
+			delete(conflicts, k)
+		}
+		dst.set(e)
+		delete(dups, k)
+	}
+	res := make([]OverrideConflict, 0, len(conflicts))
+	for _, c := range conflicts {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Base.Assignment().less(res[j].Base.Assignment())
+	})
+	db := newDynamic(dst, dups, o)
+	db.generatedAt(t)
+	return db, res, nil
+}
@@ -0,0 +1,81 @@
+package oui
+
+import "sync"
+
+// Parent organizations set with SetParentMapping.
+// The zero value has no mapping, so no parents are set.
+type parentMap struct {
+	mu sync.RWMutex
+	// The mapping as given, kept to normalize it again if the normalizer changes.
+	mapping map[string]string
+	// The normalizer set with SetNameNormalizer, or nil.
+	normalizer func(string) string
+	// Parents keyed by the normalized name of the organization.
+	parents map[string]string
+}
+
+// Normalize a manufacturer name like Entry.NormalizedManufacturer,
+// with the normalizer of the database.
+// Assumes mutex is locked by caller.
+func (p *parentMap) normalize(s string) string {
+	return Entry{Manufacturer: s, normalizer: p.normalizer}.NormalizedManufacturer()
+}
+
+// Replace the mapping. A nil or empty mapping removes it.
+func (p *parentMap) set(m map[string]string) {
+	var mapping map[string]string
+	if len(m) > 0 {
+		mapping = make(map[string]string, len(m))
+		for org, parent := range m {
+			mapping[org] = parent
+		}
+	}
+	p.mu.Lock()
+	p.mapping = mapping
+	p.index()
+	p.mu.Unlock()
+}
+
+// Set the normalizer names are compared with, and normalize the mapping again.
+func (p *parentMap) setNormalizer(fn func(string) string) {
+	p.mu.Lock()
+	p.normalizer = fn
+	p.index()
+	p.mu.Unlock()
+}
+
+// Key the parents of the mapping by the normalized name of the organizations.
+// Assumes mutex is locked by caller.
+func (p *parentMap) index() {
+	p.parents = nil
+	if len(p.mapping) == 0 {
+		return
+	}
+	p.parents = make(map[string]string, len(p.mapping))
+	for org, parent := range p.mapping {
+		p.parents[p.normalize(org)] = parent
+	}
+}
+
+// Set the ultimate parent organization of an entry returned by a lookup,
+// following the mapping until an organization without a parent is found.
+func (p *parentMap) apply(e *Entry) {
+	if p == nil {
+		return
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.parents == nil {
+		return
+	}
+	org := e.Manufacturer
+	// Stop after visiting every organization, in case the mapping has a cycle.
+	for i := 0; i < len(p.parents); i++ {
+		parent, ok := p.parents[p.normalize(org)]
+		if !ok || p.normalize(parent) == p.normalize(org) {
+			break
+		}
+		org = parent
+		e.ParentOrganization = parent
+	}
+}
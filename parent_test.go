@@ -0,0 +1,48 @@
+package oui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParentMappingNormalizer(t *testing.T) {
+	db, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hw := HardwareAddr{0x00, 0x22, 0x72}
+	parent := func() string {
+		e, err := db.LookUp(hw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e.ParentOrganization
+	}
+	db.SetParentMapping(map[string]string{
+		"american micro-fuel": "Fuel Holdings",
+		"FUEL HOLDINGS LTD":   "Energy Group",
+	})
+	if got := parent(); got != "" {
+		t.Errorf("ParentOrganization = %q without a normalizer, want none", got)
+	}
+	// The normalizer is used for the mapping set before it.
+	db.SetNameNormalizer(func(s string) string {
+		s = strings.ToLower(s)
+		for _, suffix := range []string{" device corp.", " ltd"} {
+			s = strings.TrimSuffix(s, suffix)
+		}
+		return s
+	})
+	if got := parent(); got != "Energy Group" {
+		t.Errorf("ParentOrganization = %q with a normalizer, want Energy Group", got)
+	}
+	// And for mappings set after it.
+	db.SetParentMapping(map[string]string{"AMERICAN MICRO-FUEL": "Other Holdings"})
+	if got := parent(); got != "Other Holdings" {
+		t.Errorf("ParentOrganization = %q after a new mapping, want Other Holdings", got)
+	}
+	db.SetNameNormalizer(nil)
+	if got := parent(); got != "" {
+		t.Errorf("ParentOrganization = %q after removing the normalizer, want none", got)
+	}
+}
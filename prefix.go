@@ -0,0 +1,139 @@
+package oui
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Prefix is a MAC address prefix of a given number of bits,
+// for instance "00:55:da:a0:00:00/28".
+// Bits after the prefix length are always zero.
+type Prefix struct {
+	Addr [6]byte
+	Bits int
+}
+
+// ParsePrefix will parse a MAC address with an optional "/bits" mask.
+// The address is parsed like ParseMac, but up to 6 elements are used.
+// If no mask is given, the prefix will cover the elements given,
+// so "00-55-DA" is a 24 bit prefix.
+// The number of bits must be between 1 and 48.
+func ParsePrefix(s string) (*Prefix, error) {
+	mac, mask := s, ""
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		mac, mask = s[:i], s[i+1:]
+	}
+	octets, err := parseOctets(mac, 6)
+	if err != nil {
+		return nil, err
+	}
+	p := Prefix{Bits: len(octets) * 8}
+	copy(p.Addr[:], octets)
+	if mask != "" {
+		p.Bits, err = strconv.Atoi(mask)
+		if err != nil {
+			return nil, ErrInvalidMac{Reason: fmt.Sprintf("Mask (%s) cannot be parsed as a number", mask), Mac: s}
+		}
+	}
+	if p.Bits < 1 || p.Bits > 48 {
+		return nil, ErrInvalidMac{Reason: fmt.Sprintf("Mask (%d) must be between 1 and 48 bits", p.Bits), Mac: s}
+	}
+	p = p.masked()
+	return &p, nil
+}
+
+// Return a prefix of the first bits of a hardware address.
+func prefixOf(hw HardwareAddr, bits int) Prefix {
+	return Prefix{Addr: [6]byte{hw[0], hw[1], hw[2]}, Bits: bits}.masked()
+}
+
+// Return a 48 bit prefix of a MAC address stored in the lower 48 bits of mac.
+func prefixUint64(mac uint64) Prefix {
+	return Prefix{Addr: [6]byte{byte(mac >> 40), byte(mac >> 32), byte(mac >> 24), byte(mac >> 16), byte(mac >> 8), byte(mac)}, Bits: 48}
+}
+
+// Clear the bits after the prefix length.
+func (p Prefix) masked() Prefix {
+	for i := range p.Addr {
+		switch {
+		case p.Bits >= (i+1)*8:
+		case p.Bits <= i*8:
+			p.Addr[i] = 0
+		default:
+			p.Addr[i] &= byte(0xff << uint(8-p.Bits%8))
+		}
+	}
+	return p
+}
+
+// Return the first bits of the prefix.
+func (p Prefix) truncate(bits int) Prefix {
+	p.Bits = bits
+	return p.masked()
+}
+
+// Returns true if q is within p, so q has at least as many bits,
+// and the first bits are the same.
+func (p Prefix) contains(q Prefix) bool {
+	return q.Bits >= p.Bits && q.truncate(p.Bits) == p
+}
+
+// Returns true if p sorts before q, by address and then by length.
+func (p Prefix) less(q Prefix) bool {
+	if c := bytes.Compare(p.Addr[:], q.Addr[:]); c != 0 {
+		return c < 0
+	}
+	return p.Bits < q.Bits
+}
+
+// OUI returns the first 24 bits of the prefix.
+func (p Prefix) OUI() HardwareAddr {
+	return HardwareAddr{p.Addr[0], p.Addr[1], p.Addr[2]}
+}
+
+// Extension returns the 24 bits of the prefix after the OUI.
+// It is zero for prefixes of 24 bits or less.
+func (p Prefix) Extension() HardwareAddr {
+	return HardwareAddr{p.Addr[3], p.Addr[4], p.Addr[5]}
+}
+
+// String returns the prefix as "xx:xx:xx:xx:xx:xx/bits".
+func (p Prefix) String() string {
+	a := p.Addr
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x/%d", a[0], a[1], a[2], a[3], a[4], a[5], p.Bits)
+}
+
+// The maximum number of addresses returned by PrefixMACs.
+const maxPrefixMACs = 1 << 16
+
+// PrefixMACs will return up to limit hardware addresses under the prefix,
+// in ascending order, so a 36 bit prefix contains 4096 addresses.
+// The addresses are complete 6 byte addresses.
+// Limit is capped at 65536 addresses, and if it is 0 or less,
+// nil is returned.
+// An error is returned if the prefix isn't between 1 and 48 bits.
+func PrefixMACs(prefix Prefix, limit int) ([]net.HardwareAddr, error) {
+	if prefix.Bits < 1 || prefix.Bits > 48 {
+		return nil, ErrInvalidMac{Reason: fmt.Sprintf("Mask (%d) must be between 1 and 48 bits", prefix.Bits), Mac: prefix.String()}
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+	if limit > maxPrefixMACs {
+		limit = maxPrefixMACs
+	}
+	base := prefixKey(prefix)
+	n := limit
+	if prefix.Bits > 32 && 1<<uint(48-prefix.Bits) < n {
+		n = 1 << uint(48-prefix.Bits)
+	}
+	res := make([]net.HardwareAddr, n)
+	for i := range res {
+		v := base + uint64(i)
+		res[i] = net.HardwareAddr{byte(v >> 40), byte(v >> 32), byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+	}
+	return res, nil
+}
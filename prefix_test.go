@@ -0,0 +1,36 @@
+package oui
+
+import "testing"
+
+func TestPrefixMACs(t *testing.T) {
+	p, err := ParsePrefix("70:b3:d5:f5:7a:bc/44")
+	if err != nil {
+		t.Fatal(err)
+	}
+	macs, err := PrefixMACs(*p, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(macs) != 16 {
+		t.Fatalf("PrefixMACs returned %d addresses, want 16", len(macs))
+	}
+	if got, want := macs[0].String(), "70:b3:d5:f5:7a:b0"; got != want {
+		t.Errorf("first address = %s, want %s", got, want)
+	}
+	if got, want := macs[15].String(), "70:b3:d5:f5:7a:bf"; got != want {
+		t.Errorf("last address = %s, want %s", got, want)
+	}
+	// A 24 bit prefix has more addresses than the limit.
+	macs, err = PrefixMACs(Prefix{Addr: [6]byte{0x00, 0x22, 0x72}, Bits: 24}, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(macs) != maxPrefixMACs || macs[len(macs)-1].String() != "00:22:72:00:ff:ff" {
+		t.Errorf("PrefixMACs returned %d addresses ending with %s", len(macs), macs[len(macs)-1])
+	}
+	for _, bits := range []int{0, 49} {
+		if _, err := PrefixMACs(Prefix{Bits: bits}, 10); err == nil {
+			t.Errorf("no error for a %d bit prefix", bits)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package oui
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A token bucket rate limiter per remote IP.
+type rateLimiter struct {
+	rate  float64 // Tokens added per second.
+	burst float64
+	clock Clock
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// The maximum number of buckets kept. When there are this many,
+// full buckets are removed, and if that isn't enough, the least
+// recently used buckets, so a quarter of the buckets are free.
+const maxBuckets = 10000
+
+func newRateLimiter(perSecond float64, burst int, clock Clock) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    perSecond,
+		burst:   float64(burst),
+		clock:   clock,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Take a token for the remote address.
+// Returns false if there are none left.
+func (l *rateLimiter) allow(remoteAddr string) bool {
+	ip, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		ip = remoteAddr
+	}
+	now := l.clock.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[ip]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.prune(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[ip] = b
+	}
+	b.refill(now, l.rate, l.burst)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Add the tokens accumulated since the last time.
+func (b *bucket) refill(now time.Time, rate, burst float64) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+}
+
+// Remove buckets that are full, since they are the same as new buckets.
+// If fewer than a quarter of the buckets are removed, the least recently
+// used are removed too, so pruning is only done once for every maxBuckets/4
+// new addresses, also when all buckets are in use.
+// Assumes mutex is locked by caller.
+func (l *rateLimiter) prune(now time.Time) {
+	for ip, b := range l.buckets {
+		// The bucket is not refilled, so last is the time it was used.
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, ip)
+		}
+	}
+	keep := maxBuckets * 3 / 4
+	if len(l.buckets) <= keep {
+		return
+	}
+	ips := make([]string, 0, len(l.buckets))
+	for ip := range l.buckets {
+		ips = append(ips, ip)
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		return l.buckets[ips[i]].last.Before(l.buckets[ips[j]].last)
+	})
+	for _, ip := range ips[:len(ips)-keep] {
+		delete(l.buckets, ip)
+	}
+}
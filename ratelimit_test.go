@@ -0,0 +1,30 @@
+package oui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterMaxBuckets(t *testing.T) {
+	clock := &testClock{t: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	// No tokens are added, so buckets are never full after they are used.
+	l := newRateLimiter(0, 1, clock)
+	if !l.allow("192.0.2.1:1234") {
+		t.Fatal("first request not allowed")
+	}
+	for i := 0; i < 3*maxBuckets; i++ {
+		clock.t = clock.t.Add(time.Millisecond)
+		ip := fmt.Sprintf("10.%d.%d.%d:80", byte(i>>16), byte(i>>8), byte(i))
+		if !l.allow(ip) {
+			t.Fatalf("first request from %s not allowed", ip)
+		}
+		if len(l.buckets) > maxBuckets {
+			t.Fatalf("%d buckets after %d addresses, want at most %d", len(l.buckets), i+2, maxBuckets)
+		}
+		// The most recent address is still limited.
+		if l.allow(ip) {
+			t.Fatalf("second request from %s allowed", ip)
+		}
+	}
+}
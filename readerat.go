@@ -0,0 +1,159 @@
+package oui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// The location of a record in the input.
+type span struct {
+	off int64
+	n   int64
+}
+
+// A store that only keeps the location of each record in memory.
+// Entries are read from the underlying reader when they are looked up.
+// The store is never modified.
+type fileStore struct {
+	r     io.ReaderAt
+	index prefixTrie[span]
+}
+
+// A reader records are read from, closed with the database.
+type readerAtCloser interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// errRecordMoved is returned if a record can no longer be found
+// at the location it was indexed at.
+var errRecordMoved = errors.New("record not found at indexed location, was the input modified?")
+
+// OpenStaticReaderAt will index the content of the given reader and return a database
+// that reads entries from the reader when they are looked up.
+// Only the location of each record is kept in memory, so memory usage stays low
+// regardless of the size of the database.
+// A *os.File can be given directly, and is mapped into memory on unix systems,
+// so lookups read the page cache without a system call.
+// The reader must remain open and unmodified for as long as the database is used.
+// If the reader implements io.Closer, it is closed when the database is closed.
+// Lookups after the database is closed are invalid, and will usually fail.
+// Options changing how entries are loaded are not supported, since entries
+// are read again on every lookup. WithClock and WithCapacityHint are used.
+func OpenStaticReaderAt(r io.ReaderAt, size int64, opts ...Option) (StaticDB, error) {
+	if f, ok := r.(*os.File); ok {
+		r = mapFile(f, size)
+	}
+	o := newOptions(opts)
+	st, t, err := indexFile(r, size, o.capacity)
+	db := newDatabase(st, o)
+	db.generatedAt(t)
+	if c, ok := r.(io.Closer); ok {
+		db.file = c
+	}
+	return staticDB{db}, err
+}
+
+// Index the records of a file, with room for capacity OUIs in the index.
+func indexFile(r io.ReaderAt, size int64, capacity int) (*fileStore, *time.Time, error) {
+	st := &fileStore{r: r, index: newPrefixTrie[span](capacity)}
+	t, err := scanRecords(io.NewSectionReader(r, 0, size), options{}, func(e Entry, off, n int64) error {
+		st.index.set(e.Assignment(), span{off: off, n: n})
+		return nil
+	})
+	return st, t, err
+}
+
+// Read the entry at the given location.
+func (s *fileStore) read(sp span) (*Entry, error) {
+	var found *Entry
+	_, err := scanRecords(io.NewSectionReader(s.r, sp.off, sp.n), options{}, func(e Entry, off, n int64) error {
+		found = &e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, errRecordMoved
+	}
+	return found, nil
+}
+
+func (s *fileStore) get(p Prefix) (Entry, bool, error) {
+	_, sp, ok := s.index.longest(p)
+	if !ok {
+		return Entry{}, false, nil
+	}
+	e, err := s.read(sp)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	return *e, true, nil
+}
+
+func (s *fileStore) hasLonger(p Prefix) bool {
+	return s.index.hasLonger(p)
+}
+
+func (s *fileStore) longer(p Prefix, fn func(Entry) bool) error {
+	var spans []span
+	s.index.longer(p, func(_ Prefix, sp span) bool {
+		spans = append(spans, sp)
+		return true
+	})
+	for _, sp := range spans {
+		e, err := s.read(sp)
+		if err != nil {
+			return err
+		}
+		if !fn(*e) {
+			break
+		}
+	}
+	return nil
+}
+
+// Duplicates are not kept for entries read from a file.
+func (s *fileStore) shadowed(k Prefix) []Entry {
+	return nil
+}
+
+// Entries are read from the file in the order of the index.
+// If an entry cannot be read, the error is returned.
+func (s *fileStore) walk(fn func(Entry) bool) error {
+	return s.walkKeys(func(_ Prefix, e Entry) bool {
+		return fn(e)
+	})
+}
+
+// Like walk, but the key of each entry in the index is also given.
+func (s *fileStore) walkKeys(fn func(Prefix, Entry) bool) error {
+	var err error
+	s.index.walk(func(k Prefix, sp span) bool {
+		var e *Entry
+		if e, err = s.read(sp); err != nil {
+			return false
+		}
+		return fn(k, *e)
+	})
+	return err
+}
+
+func (s *fileStore) len() int {
+	return s.index.len()
+}
+
+func (s *fileStore) clone() store {
+	return s
+}
+
+func (s *fileStore) memBytes() int64 {
+	return trieBytes(&s.index)
+}
+
+func (s *fileStore) blocking() bool {
+	return true
+}
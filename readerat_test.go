@@ -0,0 +1,145 @@
+package oui
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"testing"
+)
+
+// Hides the *os.File, so it is read with ReadAt instead of being mapped.
+type plainReaderAt struct {
+	io.ReaderAt
+	io.Closer
+}
+
+func TestOpenStaticReaderAtClosed(t *testing.T) {
+	f, err := os.Open("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenStaticReaderAt(f, st.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := db.LookUp(HardwareAddr{0x00, 0x22, 0x72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != "American Micro-Fuel Device Corp." {
+		t.Errorf("LookUp = %q", e.Manufacturer)
+	}
+	if err := db.(io.Closer).Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The file is no longer mapped, so the lookup must fail instead of crashing.
+	if _, err := db.LookUp(HardwareAddr{0x00, 0x22, 0x72}); err == nil {
+		t.Error("no error looking up an entry after the database was closed")
+	}
+}
+
+// Report the heap used by the database returned by open.
+func benchmarkLookUp(b *testing.B, open func() (OuiDB, error)) {
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	db, err := open()
+	if err != nil {
+		b.Fatal(err)
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	hw := []HardwareAddr{{0x00, 0x22, 0x72}, {0x00, 0x60, 0x92}, {0x70, 0xb3, 0xd5}}
+	if _, err := db.LookUp(hw[0]); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		db.LookUp(hw[i%len(hw)])
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(after.HeapAlloc)-float64(before.HeapAlloc), "heap-bytes")
+	runtime.KeepAlive(db)
+	if c, ok := db.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+func BenchmarkLookUpReaderAt(b *testing.B) {
+	const name = "testdata/oui.txt"
+	openFile := func(plain bool) (OuiDB, error) {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		st, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if plain {
+			return OpenStaticReaderAt(plainReaderAt{f, f}, st.Size())
+		}
+		return OpenStaticReaderAt(f, st.Size())
+	}
+	b.Run("memory", func(b *testing.B) {
+		benchmarkLookUp(b, func() (OuiDB, error) { return OpenFile(name) })
+	})
+	b.Run("mmap", func(b *testing.B) {
+		benchmarkLookUp(b, func() (OuiDB, error) { return openFile(false) })
+	})
+	b.Run("readerat", func(b *testing.B) {
+		benchmarkLookUp(b, func() (OuiDB, error) { return openFile(true) })
+	})
+}
+
+// The number of open file descriptors, or -1 if it cannot be found.
+func openFiles() int {
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(fds)
+}
+
+func TestReloadDoesNotLeak(t *testing.T) {
+	before := openFiles()
+	if before < 0 {
+		t.Skip("open files cannot be counted")
+	}
+	for i := 0; i < 100; i++ {
+		f, err := os.Open("testdata/oui.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		st, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		static, err := OpenStaticReaderAt(f, st.Size())
+		if err != nil {
+			t.Fatal(err)
+		}
+		mutable, err := OpenStaticMutable("testdata/oui.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, db := range []io.Closer{static.(io.Closer), mutable.(io.Closer)} {
+			if err := db.Close(); err != nil {
+				t.Fatal(err)
+			}
+			// Closing again is safe.
+			if err := db.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if after := openFiles(); after > before {
+		t.Errorf("%d files open after reloading, %d before", after, before)
+	}
+}
@@ -0,0 +1,57 @@
+package oui
+
+// Registry is an IEEE registry that assignments are made from.
+type Registry string
+
+const (
+	// RegistryMAL is the MA-L registry of 24 bit assignments, published as oui.txt.
+	RegistryMAL Registry = "MA-L"
+	// RegistryMAM is the MA-M registry of 28 bit assignments, published as mam.txt.
+	RegistryMAM Registry = "MA-M"
+	// RegistryMAS is the MA-S registry of 36 bit assignments, published as oui36.txt.
+	RegistryMAS Registry = "MA-S"
+	// RegistryIAB is the closed IAB registry of 36 bit assignments, published as iab.txt.
+	RegistryIAB Registry = "IAB"
+)
+
+// RegistryURLs are the official download locations of the registries in the oui.txt format.
+var RegistryURLs = map[Registry]string{
+	RegistryMAL: "https://standards-oui.ieee.org/oui/oui.txt",
+	RegistryMAM: "https://standards-oui.ieee.org/oui28/mam.txt",
+	RegistryMAS: "https://standards-oui.ieee.org/oui36/oui36.txt",
+	RegistryIAB: "https://standards-oui.ieee.org/iab/iab.txt",
+}
+
+// 24 bit prefixes the IAB assignments were made from.
+var iabPrefixes = map[HardwareAddr]struct{}{
+	{0x00, 0x50, 0xc2}: {},
+	{0x40, 0xd8, 0x55}: {},
+}
+
+// Registry returns the registry the entry is assigned from, based on the prefix length.
+// Entries without a known prefix length are considered to be MA-L assignments.
+func (e Entry) Registry() Registry {
+	switch bits := e.bits(); {
+	case bits > 28:
+		if _, ok := iabPrefixes[e.Prefix]; ok {
+			return RegistryIAB
+		}
+		return RegistryMAS
+	case bits > 24:
+		return RegistryMAM
+	}
+	return RegistryMAL
+}
+
+// HasRegistry returns true if the database contains entries assigned
+// from the registry, so it can be checked whether the finer grained MA-M
+// and MA-S registries have been loaded.
+// Entries shadowed by another entry for the same assignment are not considered.
+func HasRegistry(db OuiDB, r Registry) bool {
+	found := false
+	db.walk(func(e Entry) bool {
+		found = e.Registry() == r
+		return !found
+	})
+	return found
+}
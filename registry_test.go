@@ -0,0 +1,92 @@
+package oui
+
+import (
+	"os"
+	"testing"
+)
+
+// Open the MA-L test file together with the MA-M and MA-S excerpts.
+func openRegistries(t testing.TB, names ...string) DynamicDB {
+	t.Helper()
+	var b []byte
+	for _, name := range names {
+		f, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b = append(b, f...)
+	}
+	db, err := OpenBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestHasRegistry(t *testing.T) {
+	db := openRegistries(t, "oui.txt")
+	if !HasRegistry(db, RegistryMAL) || HasRegistry(db, RegistryMAM) || HasRegistry(db, RegistryMAS) {
+		t.Error("oui.txt should only have MA-L entries")
+	}
+	db = openRegistries(t, "oui.txt", "mam.txt", "oui36.txt")
+	for _, r := range []Registry{RegistryMAL, RegistryMAM, RegistryMAS} {
+		if !HasRegistry(db, r) {
+			t.Errorf("HasRegistry(%s) = false", r)
+		}
+	}
+	if HasRegistry(db, RegistryIAB) {
+		t.Error("HasRegistry(IAB) = true")
+	}
+	e, err := db.Query("00:55:DA:A1:23:45")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != "Speechlab" || e.PrefixLen != 28 || e.Registry() != RegistryMAM {
+		t.Errorf("Query = %q/%d from %s, want the Speechlab MA-M entry", e.Manufacturer, e.PrefixLen, e.Registry())
+	}
+	e, err = db.Query("00:55:DA:F1:23:45")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.IsPrivate {
+		t.Errorf("Query = %q, want the private MA-M entry", e.Manufacturer)
+	}
+}
+
+func TestLookUpLevels(t *testing.T) {
+	db := openRegistries(t, "oui.txt", "mam.txt", "oui36.txt")
+	tests := []struct {
+		mac  uint64
+		bits []int
+		want string
+	}{
+		{mac: 0x70b3d50e0123, want: "Grossenbacher Systeme AG"},
+		{mac: 0x70b3d50e0123, bits: []int{36}, want: "Grossenbacher Systeme AG"},
+		{mac: 0x70b3d50e0123, bits: []int{24}, want: registrationAuthority},
+		{mac: 0x0055daa12345, bits: []int{24, 28}, want: "Speechlab"},
+		{mac: 0x0055daa12345, bits: []int{36}},
+		{mac: 0x001bc5000123, bits: []int{28}},
+	}
+	for _, test := range tests {
+		e, err := LookUpLevelsUint64(db, test.mac, test.bits...)
+		switch {
+		case test.want == "" && err == nil:
+			t.Errorf("LookUpLevelsUint64(%012x, %v) = %q, want not found", test.mac, test.bits, e.Manufacturer)
+		case test.want != "" && err != nil:
+			t.Errorf("LookUpLevelsUint64(%012x, %v): %v", test.mac, test.bits, err)
+		case test.want != "" && e.Manufacturer != test.want:
+			t.Errorf("LookUpLevelsUint64(%012x, %v) = %q, want %q", test.mac, test.bits, e.Manufacturer, test.want)
+		}
+	}
+	// Only the OUI is known, so the MA-S entries within it are not returned.
+	e, err := LookUpLevels(db, HardwareAddr{0x70, 0xb3, 0xd5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != registrationAuthority {
+		t.Errorf("LookUpLevels = %q, want %q", e.Manufacturer, registrationAuthority)
+	}
+	if _, err := LookUpLevels(db, HardwareAddr{0x70, 0xb3, 0xd5}, 36); err == nil {
+		t.Error("LookUpLevels with only MA-S allowed found an entry for an OUI")
+	}
+}
@@ -0,0 +1,182 @@
+package oui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteFallbackDB is a database that looks up addresses in a local database,
+// and queries a remote lookup service for addresses that are not found locally.
+// Results from the remote service are cached.
+// It is safe for concurrent use.
+type RemoteFallbackDB struct {
+	local ReadOnlyDB
+	url   string
+
+	// Client is used for requests. If nil http.DefaultClient is used.
+	Client *http.Client
+
+	// Timeout is the maximum time a remote lookup may take.
+	// If 0, the remote lookup is only limited by the context or the client.
+	Timeout time.Duration
+
+	// NegativeTTL is how long an address the remote service doesn't know
+	// is remembered, before the service is asked again.
+	// If 0, addresses that are not found are not cached.
+	NegativeTTL time.Duration
+
+	// MaxCacheSize is the maximum number of addresses cached.
+	// When the cache is full, an arbitrary address is removed to make room.
+	// If 0, the cache is not limited.
+	MaxCacheSize int
+
+	// Clock is used for expiring cached results. If nil SystemClock is used.
+	Clock Clock
+
+	mu    sync.RWMutex
+	cache map[HardwareAddr]remoteResult
+}
+
+// A cached result from the remote service.
+// A nil entry means the address wasn't found, until expires.
+type remoteResult struct {
+	e       *Entry
+	expires time.Time
+}
+
+// Check we implement the interfaces we promise
+var _ ReadOnlyDB = &RemoteFallbackDB{}
+
+// NewRemoteFallbackDB will return a database that looks up addresses in local,
+// and on a miss queries the service at url.
+// The service must respond like NewHTTPHandler, or the App Engine server,
+// so the address is appended to url as a path, or as the "mac" parameter
+// if url contains a '?', for instance "http://mac-oui.appspot.com/".
+//
+// Addresses that the remote service doesn't know are not cached,
+// so they will be queried again, unless NegativeTTL is set.
+func NewRemoteFallbackDB(local ReadOnlyDB, url string) *RemoteFallbackDB {
+	return &RemoteFallbackDB{local: local, url: url, cache: make(map[HardwareAddr]remoteResult)}
+}
+
+// Query the database for an entry based on the mac address
+// If none are found a NotFoundError will be returned.
+func (r *RemoteFallbackDB) Query(mac string) (*Entry, error) {
+	hw, err := ParseMac(mac)
+	if err != nil {
+		return nil, err
+	}
+	return r.LookUp(*hw)
+}
+
+// LookUp a hardware address and return the entry if any are found.
+// The local database is consulted first, then the cache and the remote service.
+// If none are found a NotFoundError will be returned.
+func (r *RemoteFallbackDB) LookUp(hw HardwareAddr) (*Entry, error) {
+	return r.LookUpContext(context.Background(), hw)
+}
+
+// LookUpContext will look up a hardware address like LookUp.
+// The remote request is cancelled if ctx is done before it completes.
+func (r *RemoteFallbackDB) LookUpContext(ctx context.Context, hw HardwareAddr) (*Entry, error) {
+	e, err := r.local.LookUp(hw)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return e, err
+	}
+	clock := r.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	r.mu.RLock()
+	res, ok := r.cache[hw]
+	r.mu.RUnlock()
+	switch {
+	case ok && res.e != nil:
+		c := *res.e
+		return &c, nil
+	case ok && clock.Now().Before(res.expires):
+		return nil, NotFoundError{Addr: hw}
+	}
+	e, err = r.remote(ctx, hw)
+	switch {
+	case err == nil:
+		r.store(hw, remoteResult{e: e})
+	case errors.Is(err, ErrNotFound) && r.NegativeTTL > 0:
+		r.store(hw, remoteResult{expires: clock.Now().Add(r.NegativeTTL)})
+		return nil, err
+	default:
+		return nil, err
+	}
+	c := *e
+	return &c, nil
+}
+
+// Store a result in the cache, making room for it if the cache is full.
+func (r *RemoteFallbackDB) store(hw HardwareAddr, res remoteResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cache[hw]; !ok && r.MaxCacheSize > 0 {
+		for k := range r.cache {
+			if len(r.cache) < r.MaxCacheSize {
+				break
+			}
+			delete(r.cache, k)
+		}
+	}
+	r.cache[hw] = res
+}
+
+// Query the remote service for the address.
+func (r *RemoteFallbackDB) remote(ctx context.Context, hw HardwareAddr) (*Entry, error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	url := strings.TrimSuffix(r.url, "/") + "/" + hw.String()
+	if strings.Contains(r.url, "?") {
+		url = r.url + "&mac=" + hw.String()
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, NotFoundError{Addr: hw}
+	default:
+		return nil, fmt.Errorf("unexpected status looking up %s: %s", hw, resp.Status)
+	}
+	var res struct {
+		Data  *Entry `json:"data"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	if res.Data == nil {
+		return nil, NotFoundError{Addr: hw}
+	}
+	return res.Data, nil
+}
+
+// Get the generated time of the local database.
+func (r *RemoteFallbackDB) Generated() time.Time {
+	return r.local.Generated()
+}
@@ -0,0 +1,133 @@
+package oui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// SearchOption can be given to Search to change how manufacturers are matched.
+type SearchOption func(*searchOptions)
+
+type searchOptions struct {
+	matchCase     bool
+	ignoreAccents bool
+	// Set from SetNameNormalizer when searching.
+	custom func(string) string
+}
+
+// MatchCase will make Search case sensitive.
+// By default the query and manufacturer are case folded before they are compared.
+func MatchCase() SearchOption {
+	return func(o *searchOptions) {
+		o.matchCase = true
+	}
+}
+
+// IgnoreAccents will make Search ignore accents, so "Sao" matches "São".
+func IgnoreAccents() SearchOption {
+	return func(o *searchOptions) {
+		o.ignoreAccents = true
+	}
+}
+
+// Normalize a string for comparison.
+// The string is NFKC normalized, so compatibility forms like full-width
+// characters are compared as their regular forms.
+func (o searchOptions) normalize(s string) string {
+	if o.custom != nil {
+		s = o.custom(s)
+	}
+	if o.ignoreAccents {
+		t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+		if r, _, err := transform.String(t, s); err == nil {
+			s = r
+		}
+	}
+	s = norm.NFKC.String(s)
+	if !o.matchCase {
+		s = cases.Fold().String(s)
+	}
+	return s
+}
+
+// Search the database for entries with a manufacturer containing the query.
+// See the SearchOption functions for options.
+// The result is sorted by prefix.
+func Search(db OuiDB, query string, opts ...SearchOption) ([]*Entry, error) {
+	o := searchOptions{custom: db.nameNormalizer()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	query = o.normalize(query)
+	var res []*Entry
+	err := db.walk(func(e Entry) bool {
+		if strings.Contains(o.normalize(e.Manufacturer), query) {
+			res = append(res, &e)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortEntries(res)
+	return res, nil
+}
+
+// walker is implemented by all database types.
+type walker interface {
+	walk(func(Entry) bool) error
+}
+
+// Sort entries by prefix.
+// The sort is stable, so entries with the same prefix keep their order.
+func sortEntries(e []*Entry) {
+	sort.SliceStable(e, func(i, j int) bool {
+		return e[i].Assignment().less(e[j].Assignment())
+	})
+}
+
+// LookUpRegistrationID returns all entries with the registration ID, sorted by prefix.
+// IDs must match exactly. If no entries have the ID, or the ID is empty,
+// an empty result is returned.
+func LookUpRegistrationID(db OuiDB, id string) ([]*Entry, error) {
+	var res []*Entry
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return res, nil
+	}
+	err := db.walk(func(e Entry) bool {
+		if e.RegistrationID == id {
+			res = append(res, &e)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortEntries(res)
+	return res, nil
+}
+
+// LookUpByTag returns all entries with the tag, sorted by prefix.
+// Tags are compared case insensitively.
+// If no entries have the tag, an empty result is returned.
+func LookUpByTag(db OuiDB, tag string) ([]*Entry, error) {
+	var res []*Entry
+	err := db.walk(func(e Entry) bool {
+		if e.HasTag(tag) {
+			res = append(res, &e)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortEntries(res)
+	return res, nil
+}
@@ -0,0 +1,141 @@
+package oui
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetNameNormalizer(t *testing.T) {
+	db, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := HardwareAddr{0x02, 0x00, 0x01}, HardwareAddr{0x02, 0x00, 0x02}
+	for _, d := range []DynamicDB{db, other} {
+		d.UpdateEntry(a, Entry{Prefix: a, Manufacturer: "Acme Inc."})
+		d.UpdateEntry(b, Entry{Prefix: b, Manufacturer: "ACME"})
+	}
+	db.SetNameNormalizer(func(s string) string {
+		return strings.ToLower(strings.TrimSuffix(s, " Inc."))
+	})
+
+	same, err := SameVendor(db, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same {
+		t.Error("SameVendor is false with the normalizer")
+	}
+	same, err = SameVendor(other, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same {
+		t.Error("the normalizer of one database changed another")
+	}
+
+	res, err := Search(db, "Acme Inc.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 {
+		t.Errorf("Search returned %d entries, want 2", len(res))
+	}
+	if n := VendorPrefixCounts(db)["acme"]; n != 2 {
+		t.Errorf("VendorPrefixCounts[acme] = %d, want 2", n)
+	}
+
+	db.SetNameNormalizer(nil)
+	if same, _ := SameVendor(db, a, b); same {
+		t.Error("SameVendor is true after the normalizer was removed")
+	}
+}
+
+func TestOrder(t *testing.T) {
+	// Records in the file are not in prefix order.
+	text := record("70-B3-D5", "5A0000-5A0FFF", "Second Sensors") +
+		record("00-00-02", "", "Acme") +
+		record("70-B3-D5", "", "IEEE Registration Authority") +
+		record("00-00-01", "", "Acme") +
+		record("70-B3-D5", "001000-001FFF", "First Sensors") +
+		record("00-50-C2", "", "IEEE Registration Authority")
+	name := filepath.Join(t.TempDir(), "oui.txt")
+	if err := os.WriteFile(name, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mutable, err := OpenStaticMutable(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mutable.(io.Closer).Close()
+	// Entries added to the overlay are merged with those in the file.
+	mutable.UpdateEntry(HardwareAddr{0x00, 0x00, 0x03}, Entry{Prefix: HardwareAddr{0x00, 0x00, 0x03}, Manufacturer: "Acme"})
+	text += record("00-00-03", "", "Acme")
+	static, err := OpenStaticBytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dynamic, err := OpenBytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantIterate := []string{
+		"00:00:01:00:00:00/24 Acme",
+		"00:00:02:00:00:00/24 Acme",
+		"00:00:03:00:00:00/24 Acme",
+		"00:50:c2:00:00:00/24 IEEE Registration Authority",
+		"70:b3:d5:00:00:00/24 IEEE Registration Authority",
+		"70:b3:d5:00:10:00/36 First Sensors",
+		"70:b3:d5:5a:00:00/36 Second Sensors",
+	}
+	wantSearch := []string{"00:50:c2:00:00:00/24", "70:b3:d5:00:00:00/24", "70:b3:d5:00:10:00/36", "70:b3:d5:5a:00:00/36"}
+	wantManufacturers := []string{"Acme", "First Sensors", "IEEE Registration Authority", "Second Sensors"}
+	wantCoalesce := []string{"00-00-01 - 00-00-03 Acme", "00-50-C2 IEEE Registration Authority", "70-B3-D5 IEEE Registration Authority"}
+	for _, db := range []OuiDB{dynamic, static, mutable} {
+		for i := 0; i < 10; i++ {
+			var got []string
+			if err := db.Iterate(func(e *Entry) bool {
+				got = append(got, e.Assignment().String()+" "+e.Manufacturer)
+				return true
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, wantIterate) {
+				t.Fatalf("%T: Iterate = %q, want %q", db, got, wantIterate)
+			}
+
+			res, err := Search(db, "s")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = nil
+			for _, e := range res {
+				got = append(got, e.Assignment().String())
+			}
+			if !reflect.DeepEqual(got, wantSearch) {
+				t.Fatalf("%T: Search = %q, want %q", db, got, wantSearch)
+			}
+
+			if got := Manufacturers(db); !reflect.DeepEqual(got, wantManufacturers) {
+				t.Fatalf("%T: Manufacturers = %q, want %q", db, got, wantManufacturers)
+			}
+
+			got = nil
+			for _, r := range Coalesce(db) {
+				got = append(got, r.String())
+			}
+			if !reflect.DeepEqual(got, wantCoalesce) {
+				t.Fatalf("%T: Coalesce = %q, want %q", db, got, wantCoalesce)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package oui
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoRegistrationDates is returned by EntriesSince if no entries in the
+// database have a registration date. See Entry.Registered.
+var ErrNoRegistrationDates = errors.New("database has no registration dates")
+
+// EntriesSince returns the entries registered or updated at t or later, sorted by prefix,
+// using Entry.Registered. Entries without a registration date are left out.
+// Only CSV files have dates, so if no entries have one,
+// ErrNoRegistrationDates is returned.
+func EntriesSince(db OuiDB, t time.Time) ([]*Entry, error) {
+	res := []*Entry{}
+	dated := false
+	err := db.walk(func(e Entry) bool {
+		if e.Registered.IsZero() {
+			return true
+		}
+		dated = true
+		if !e.Registered.Before(t) {
+			res = append(res, &e)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !dated {
+		return nil, ErrNoRegistrationDates
+	}
+	sortEntries(res)
+	return res, nil
+}
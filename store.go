@@ -0,0 +1,140 @@
+package oui
+
+// store holds the entries of a database.
+// The database serializes access to the store, so writes are never
+// made at the same time as other calls, except for walks on a snapshot
+// returned by clone.
+type store interface {
+	// get returns the entry with the longest assignment containing p.
+	get(p Prefix) (Entry, bool, error)
+
+	// hasLonger returns true if there are entries with
+	// longer assignments than p within p.
+	hasLonger(p Prefix) bool
+
+	// longer calls fn for the entries with longer assignments
+	// than p within p, until it returns false.
+	longer(p Prefix, fn func(Entry) bool) error
+
+	// shadowed returns the entries kept for the assignment,
+	// that are shadowed by the entry stored for it.
+	shadowed(k Prefix) []Entry
+
+	// walk calls fn for all entries until it returns false.
+	walk(fn func(Entry) bool) error
+
+	// len returns the number of entries.
+	len() int
+
+	// clone returns a store with the same entries, which can be modified
+	// without affecting walks in progress on the store.
+	// Stores that are never modified can return themselves.
+	clone() store
+
+	// memBytes returns an estimate of the memory used by the store.
+	memBytes() int64
+
+	// blocking returns true if get can block, like when entries are read from a file.
+	blocking() bool
+}
+
+// writableStore is a store that entries can be added to and removed from.
+type writableStore interface {
+	store
+
+	// set stores the entry for its assignment, removing other entries kept for it.
+	set(e Entry) error
+
+	// del removes all entries kept for the assignment.
+	del(k Prefix) error
+
+	// replace returns a store with only the given entries.
+	// Stores that can be modified by walks in progress must return a new store,
+	// while stores kept outside of memory can replace their own content.
+	replace(db ouiDB, dups duplicates) (writableStore, error)
+}
+
+// A store keeping entries in memory.
+type memStore struct {
+	db   prefixTrie[Entry]
+	dups duplicates
+	// The capacity given with WithCapacityHint, used when the content is replaced.
+	capacity int
+}
+
+func newMemStore(db ouiDB, dups duplicates, capacity int) *memStore {
+	s := &memStore{db: newPrefixTrie[Entry](capacity), dups: dups, capacity: capacity}
+	for k, e := range db {
+		s.db.set(k, e)
+	}
+	return s
+}
+
+func (s *memStore) get(p Prefix) (Entry, bool, error) {
+	_, e, ok := s.db.longest(p)
+	return e, ok, nil
+}
+
+func (s *memStore) hasLonger(p Prefix) bool {
+	return s.db.hasLonger(p)
+}
+
+func (s *memStore) longer(p Prefix, fn func(Entry) bool) error {
+	s.db.longer(p, func(_ Prefix, e Entry) bool {
+		return fn(e)
+	})
+	return nil
+}
+
+func (s *memStore) shadowed(k Prefix) []Entry {
+	return s.dups[k]
+}
+
+func (s *memStore) walk(fn func(Entry) bool) error {
+	s.db.walk(func(_ Prefix, e Entry) bool {
+		return fn(e)
+	})
+	return nil
+}
+
+func (s *memStore) len() int {
+	return s.db.len()
+}
+
+// The shadowed entries are never modified in place, so they are shared.
+func (s *memStore) clone() store {
+	c := &memStore{db: s.db.clone(), capacity: s.capacity}
+	if s.dups != nil {
+		c.dups = make(duplicates, len(s.dups))
+		for k, d := range s.dups {
+			c.dups[k] = d
+		}
+	}
+	return c
+}
+
+func (s *memStore) memBytes() int64 {
+	return entriesBytes(&s.db, s.dups)
+}
+
+func (s *memStore) blocking() bool {
+	return false
+}
+
+func (s *memStore) set(e Entry) error {
+	k := e.Assignment()
+	s.db.set(k, e)
+	delete(s.dups, k)
+	return nil
+}
+
+func (s *memStore) del(k Prefix) error {
+	s.db.del(k)
+	delete(s.dups, k)
+	return nil
+}
+
+// Walks may hold the store, so a new store is returned.
+func (s *memStore) replace(db ouiDB, dups duplicates) (writableStore, error) {
+	return newMemStore(db, dups, s.capacity), nil
+}
@@ -0,0 +1,26 @@
+package oui
+
+import "time"
+
+// NewTestDB will return a database with the given entries, without parsing a file,
+// so tests can be written against the lookup functions without test data.
+// The entries are loaded like the records of a file, so Local, Multicast and
+// IsPrivate are set from the prefix and manufacturer, and a later entry
+// with the same prefix replaces an earlier one.
+// The database has no generation time, and can be updated like any other.
+func NewTestDB(entries ...Entry) DynamicDB {
+	o := newOptions(nil)
+	dst := o.newDB()
+	t, dups, _ := loadRecords(func(fn recordFunc) (*time.Time, error) {
+		for _, e := range entries {
+			setFlags(&e)
+			if err := fn(e, 0, 0); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}, dst, o)
+	db := newDynamic(dst, dups, o)
+	db.generatedAt(t)
+	return db
+}
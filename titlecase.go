@@ -0,0 +1,91 @@
+package oui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Corporate and other tokens with a fixed casing, keyed by the lower case token.
+var titleTokens = map[string]string{
+	"ab": "AB", "ag": "AG", "bv": "BV", "co": "Co", "corp": "Corp", "gmbh": "GmbH",
+	"ieee": "IEEE", "inc": "Inc", "kg": "KG", "kk": "KK", "llc": "LLC", "llp": "LLP",
+	"lp": "LP", "ltd": "Ltd", "nv": "NV", "oy": "Oy", "plc": "PLC", "pte": "Pte",
+	"pty": "Pty", "sa": "SA", "spa": "SpA", "srl": "SRL", "uk": "UK", "usa": "USA",
+}
+
+// Words that are kept lower case, unless they start the name.
+var titleSmallWords = map[string]struct{}{
+	"and": {}, "de": {}, "der": {}, "for": {}, "of": {}, "the": {}, "und": {},
+}
+
+// TitleCaseManufacturer returns the manufacturer with a casing suitable for display,
+// so "APPLE, INC." and "apple inc" both become "Apple, Inc.".
+// The Manufacturer field is not changed. The following heuristics are used:
+//   - Runs of whitespace are replaced by a single space.
+//   - Corporate tokens like Inc, LLC and GmbH get their usual casing,
+//     and punctuation around them, like "Inc.", is kept.
+//   - Small words like "of" and "and" are lower case, unless they are the first word.
+//   - Words containing digits, like "3Com", are kept as they are.
+//   - If the name has both upper and lower case letters, words with upper case
+//     letters after the first, like "NetGear" or "IBM", are kept as they are,
+//     since they are probably cased on purpose. Acronyms in names written only
+//     in upper case cannot be detected, and are title cased like other words.
+//   - Other words get an upper case first letter and lower case for the rest,
+//     also after a hyphen or a slash, like "Hewlett-Packard".
+func (e Entry) TitleCaseManufacturer() string {
+	words := strings.Fields(e.Manufacturer)
+	mixed := strings.ToUpper(e.Manufacturer) != e.Manufacturer && strings.ToLower(e.Manufacturer) != e.Manufacturer
+	for i, w := range words {
+		words[i] = titleWord(w, i == 0, mixed)
+	}
+	return strings.Join(words, " ")
+}
+
+// Title case a single word, keeping punctuation around it.
+func titleWord(w string, first, mixed bool) string {
+	start := strings.IndexFunc(w, isWordRune)
+	if start < 0 {
+		return w
+	}
+	end := strings.LastIndexFunc(w, isWordRune)
+	_, size := utf8.DecodeRuneInString(w[end:])
+	end += size
+	core := w[start:end]
+	lower := strings.ToLower(core)
+	if t, ok := titleTokens[lower]; ok {
+		return w[:start] + t + w[end:]
+	}
+	if _, ok := titleSmallWords[lower]; ok && !first {
+		return w[:start] + lower + w[end:]
+	}
+	if strings.IndexFunc(core, unicode.IsDigit) >= 0 {
+		return w
+	}
+	if mixed {
+		// Upper case letters after the first are probably on purpose.
+		_, size := utf8.DecodeRuneInString(core)
+		if strings.IndexFunc(core[size:], unicode.IsUpper) >= 0 {
+			return w
+		}
+	}
+	// Capitalize every part of hyphenated and slashed words.
+	var b strings.Builder
+	upper := true
+	for _, r := range lower {
+		if upper && unicode.IsLetter(r) {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		if r == '-' || r == '/' {
+			upper = true
+		}
+		b.WriteRune(r)
+	}
+	return w[:start] + b.String() + w[end:]
+}
+
+// Letters and digits are part of a word, punctuation around them is not.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
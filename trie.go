@@ -0,0 +1,357 @@
+package oui
+
+import (
+	"bytes"
+	"math/bits"
+	"sort"
+)
+
+// prefixTrie holds values for prefixes between 1 and 48 bits, and finds the
+// longest prefix containing an address in a single pass down the trie.
+//
+// It is a path compressed binary trie over the 48 bit address space, where the
+// first 24 bits are a single level indexed by a map, since almost all prefixes
+// are 24 bits or longer. Each OUI has its own trie holding the prefixes of 24 bits
+// or more within it, so the 24 bit entry and the MA-M and MA-S entries of an OUI
+// are found in the same pass. Prefixes shorter than 24 bits are kept in a separate
+// trie, which is consulted if the OUI has no prefix containing the address.
+type prefixTrie[T any] struct {
+	ouis  map[HardwareAddr]*trieNode[T]
+	short *trieNode[T]
+	n     int
+}
+
+// A node of a path compressed binary trie.
+// Nodes without a value always have two children,
+// so every subtree holds at least one value.
+type trieNode[T any] struct {
+	// The address of the prefix in the lower 48 bits, with the bits after the prefix cleared.
+	key   uint64
+	bits  int
+	set   bool
+	value T
+	child [2]*trieNode[T]
+}
+
+// Create a trie with room for capacity OUIs.
+func newPrefixTrie[T any](capacity int) prefixTrie[T] {
+	return prefixTrie[T]{ouis: make(map[HardwareAddr]*trieNode[T], capacity)}
+}
+
+// The address of a prefix as a number in the lower 48 bits, like LookUpUint64 takes.
+func prefixKey(p Prefix) uint64 {
+	a := p.Addr
+	key := uint64(a[0])<<40 | uint64(a[1])<<32 | uint64(a[2])<<24 | uint64(a[3])<<16 | uint64(a[4])<<8 | uint64(a[5])
+	return maskKey(key, p.Bits)
+}
+
+// The prefix of the first bits of key.
+func keyPrefix(key uint64, bits int) Prefix {
+	return Prefix{Addr: [6]byte{byte(key >> 40), byte(key >> 32), byte(key >> 24), byte(key >> 16), byte(key >> 8), byte(key)}, Bits: bits}
+}
+
+// Clear the bits of key after the first n.
+func maskKey(key uint64, n int) uint64 {
+	return key &^ (1<<uint(48-n) - 1)
+}
+
+// The bit of key after the first n.
+func keyBit(key uint64, n int) int {
+	return int(key>>uint(47-n)) & 1
+}
+
+// The number of leading bits a and b have in common, up to max.
+func commonBits(a, b uint64, max int) int {
+	if n := bits.LeadingZeros64(a^b) - 16; n < max {
+		return n
+	}
+	return max
+}
+
+// The trie holding prefix p.
+func (t *prefixTrie[T]) trie(p Prefix) *trieNode[T] {
+	if p.Bits >= 24 {
+		return t.ouis[p.OUI()]
+	}
+	return t.short
+}
+
+// get returns the value stored for exactly the prefix k.
+func (t *prefixTrie[T]) get(k Prefix) (T, bool) {
+	key := prefixKey(k)
+	node := t.trie(k)
+	for node != nil && node.bits < k.Bits && maskKey(key, node.bits) == node.key {
+		node = node.child[keyBit(key, node.bits)]
+	}
+	if node == nil || !node.set || node.bits != k.Bits || node.key != key {
+		var v T
+		return v, false
+	}
+	return node.value, true
+}
+
+func (t *prefixTrie[T]) set(k Prefix, v T) {
+	key := prefixKey(k)
+	added := false
+	if k.Bits >= 24 {
+		oui := k.OUI()
+		root := t.ouis[oui]
+		added = trieInsert(&root, key, k.Bits, v)
+		t.ouis[oui] = root
+	} else {
+		added = trieInsert(&t.short, key, k.Bits, v)
+	}
+	if added {
+		t.n++
+	}
+}
+
+func (t *prefixTrie[T]) del(k Prefix) {
+	key := prefixKey(k)
+	removed := false
+	if k.Bits >= 24 {
+		oui := k.OUI()
+		root, ok := t.ouis[oui]
+		if !ok {
+			return
+		}
+		if removed = trieRemove(&root, key, k.Bits); root == nil {
+			delete(t.ouis, oui)
+		} else {
+			t.ouis[oui] = root
+		}
+	} else {
+		removed = trieRemove(&t.short, key, k.Bits)
+	}
+	if removed {
+		t.n--
+	}
+}
+
+// longest returns the longest prefix stored that contains a, and its value.
+func (t *prefixTrie[T]) longest(a Prefix) (Prefix, T, bool) {
+	key := prefixKey(a)
+	var node *trieNode[T]
+	if a.Bits >= 24 {
+		node = trieLongest(t.ouis[a.OUI()], key, a.Bits)
+	}
+	if node == nil {
+		node = trieLongest(t.short, key, a.Bits)
+	}
+	if node == nil {
+		var v T
+		return Prefix{}, v, false
+	}
+	return keyPrefix(node.key, node.bits), node.value, true
+}
+
+// hasLonger returns true if a prefix longer than a is stored within a.
+func (t *prefixTrie[T]) hasLonger(a Prefix) bool {
+	found := false
+	t.longer(a, func(Prefix, T) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// longer calls fn for the prefixes longer than a stored within a,
+// until it returns false. Prefixes of an OUI are given in order.
+// For prefixes shorter than 24 bits, the tries of all OUIs are checked.
+func (t *prefixTrie[T]) longer(a Prefix, fn func(Prefix, T) bool) {
+	key := prefixKey(a)
+	visit := func(node *trieNode[T]) bool {
+		return node.bits == a.Bits || fn(keyPrefix(node.key, node.bits), node.value)
+	}
+	if a.Bits >= 24 {
+		trieWalk(trieWithin(t.ouis[a.OUI()], key, a.Bits), visit)
+		return
+	}
+	if !trieWalk(trieWithin(t.short, key, a.Bits), visit) {
+		return
+	}
+	for oui, root := range t.ouis {
+		if a.contains(prefixOf(oui, 24)) && !trieWalk(root, visit) {
+			return
+		}
+	}
+}
+
+// walk calls fn for all prefixes until it returns false.
+// Walk the prefixes in order, see Prefix.less.
+// The trie of each OUI is walked in order, and prefixes shorter than 24 bits
+// are given before the first OUI following them.
+func (t *prefixTrie[T]) walk(fn func(Prefix, T) bool) {
+	visit := func(node *trieNode[T]) bool {
+		return fn(keyPrefix(node.key, node.bits), node.value)
+	}
+	var short []*trieNode[T]
+	trieWalk(t.short, func(node *trieNode[T]) bool {
+		short = append(short, node)
+		return true
+	})
+	ouis := make([]HardwareAddr, 0, len(t.ouis))
+	for oui := range t.ouis {
+		ouis = append(ouis, oui)
+	}
+	sort.Slice(ouis, func(i, j int) bool {
+		return bytes.Compare(ouis[i][:], ouis[j][:]) < 0
+	})
+	for _, oui := range ouis {
+		for len(short) > 0 && keyPrefix(short[0].key, short[0].bits).less(prefixOf(oui, 24)) {
+			if !visit(short[0]) {
+				return
+			}
+			short = short[1:]
+		}
+		if !trieWalk(t.ouis[oui], visit) {
+			return
+		}
+	}
+	for _, node := range short {
+		if !visit(node) {
+			return
+		}
+	}
+}
+
+func (t *prefixTrie[T]) len() int {
+	return t.n
+}
+
+// The number of nodes of the trie, including those without a value.
+func (t *prefixTrie[T]) nodes() int {
+	n := trieNodes(t.short)
+	for _, root := range t.ouis {
+		n += trieNodes(root)
+	}
+	return n
+}
+
+func (t *prefixTrie[T]) clone() prefixTrie[T] {
+	c := prefixTrie[T]{ouis: make(map[HardwareAddr]*trieNode[T], len(t.ouis)), short: trieClone(t.short), n: t.n}
+	for oui, root := range t.ouis {
+		c.ouis[oui] = trieClone(root)
+	}
+	return c
+}
+
+// Store v for the first bits of key below n.
+// Returns true if the prefix wasn't stored before.
+func trieInsert[T any](n **trieNode[T], key uint64, bits int, v T) bool {
+	for {
+		node := *n
+		if node == nil {
+			*n = &trieNode[T]{key: key, bits: bits, set: true, value: v}
+			return true
+		}
+		common := commonBits(key, node.key, min(bits, node.bits))
+		if common < node.bits {
+			// The prefix branches off, or is a prefix of the node.
+			split := &trieNode[T]{key: maskKey(key, common), bits: common}
+			split.child[keyBit(node.key, common)] = node
+			if common == bits {
+				split.set, split.value = true, v
+			} else {
+				split.child[keyBit(key, common)] = &trieNode[T]{key: key, bits: bits, set: true, value: v}
+			}
+			*n = split
+			return true
+		}
+		if node.bits == bits {
+			added := !node.set
+			node.set, node.value = true, v
+			return added
+		}
+		n = &node.child[keyBit(key, node.bits)]
+	}
+}
+
+// Remove the value of the first bits of key below n,
+// and remove nodes left with less than two children and no value.
+// Returns true if a value was removed.
+func trieRemove[T any](n **trieNode[T], key uint64, bits int) bool {
+	node := *n
+	if node == nil || node.bits > bits || maskKey(key, node.bits) != node.key {
+		return false
+	}
+	if node.bits == bits {
+		if !node.set {
+			return false
+		}
+		var v T
+		node.set, node.value = false, v
+	} else if !trieRemove(&node.child[keyBit(key, node.bits)], key, bits) {
+		return false
+	}
+	if !node.set {
+		switch {
+		case node.child[0] == nil:
+			*n = node.child[1]
+		case node.child[1] == nil:
+			*n = node.child[0]
+		}
+	}
+	return true
+}
+
+// The node with a value and the longest prefix containing the first bits of key.
+func trieLongest[T any](node *trieNode[T], key uint64, bits int) *trieNode[T] {
+	var best *trieNode[T]
+	for node != nil && node.bits <= bits && maskKey(key, node.bits) == node.key {
+		if node.set {
+			best = node
+		}
+		if node.bits == bits {
+			break
+		}
+		node = node.child[keyBit(key, node.bits)]
+	}
+	return best
+}
+
+// The root of the subtree holding the prefixes within the first bits of key,
+// which may be a prefix of the same length, or nil if there are none.
+func trieWithin[T any](node *trieNode[T], key uint64, bits int) *trieNode[T] {
+	for node != nil {
+		if node.bits >= bits {
+			if maskKey(node.key, bits) != key {
+				return nil
+			}
+			return node
+		}
+		if maskKey(key, node.bits) != node.key {
+			return nil
+		}
+		node = node.child[keyBit(key, node.bits)]
+	}
+	return nil
+}
+
+// Call fn for the nodes with a value below node in order, until it returns false.
+// Returns false if fn did.
+func trieWalk[T any](node *trieNode[T], fn func(*trieNode[T]) bool) bool {
+	if node == nil {
+		return true
+	}
+	if node.set && !fn(node) {
+		return false
+	}
+	return trieWalk(node.child[0], fn) && trieWalk(node.child[1], fn)
+}
+
+func trieNodes[T any](node *trieNode[T]) int {
+	if node == nil {
+		return 0
+	}
+	return 1 + trieNodes(node.child[0]) + trieNodes(node.child[1])
+}
+
+func trieClone[T any](node *trieNode[T]) *trieNode[T] {
+	if node == nil {
+		return nil
+	}
+	c := *node
+	c.child = [2]*trieNode[T]{trieClone(node.child[0]), trieClone(node.child[1])}
+	return &c
+}
@@ -0,0 +1,184 @@
+package oui
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// The map approach the trie replaced: a map per prefix, and the number of
+// prefixes of each length, so lengths without prefixes are skipped by lookups.
+type lengthMap struct {
+	m    map[Prefix]int
+	lens [49]int
+}
+
+func (l *lengthMap) set(k Prefix, v int) {
+	if _, ok := l.m[k]; !ok {
+		l.lens[k.Bits]++
+	}
+	l.m[k] = v
+}
+
+func (l *lengthMap) longest(a Prefix) (Prefix, int, bool) {
+	for bits := a.Bits; bits > 0; bits-- {
+		if l.lens[bits] == 0 {
+			continue
+		}
+		k := a.truncate(bits)
+		if v, ok := l.m[k]; ok {
+			return k, v, true
+		}
+	}
+	return Prefix{}, 0, false
+}
+
+// A random prefix within a few OUIs, so prefixes overlap.
+func randomPrefix(r *rand.Rand) Prefix {
+	lengths := []int{8, 16, 20, 23, 24, 24, 28, 28, 36, 36, 40, 48}
+	p := Prefix{Addr: [6]byte{0x70, 0xb3, byte(0xd4 + r.Intn(3)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256))}}
+	if r.Intn(4) == 0 {
+		p.Bits = 1 + r.Intn(48)
+	} else {
+		p.Bits = lengths[r.Intn(len(lengths))]
+	}
+	return p.masked()
+}
+
+// Check the nodes without a value have two children.
+func checkCompact(t *testing.T, node *trieNode[int]) {
+	t.Helper()
+	if node == nil {
+		return
+	}
+	if !node.set && (node.child[0] == nil || node.child[1] == nil) {
+		t.Fatalf("node %s has no value and a single child", keyPrefix(node.key, node.bits))
+	}
+	checkCompact(t, node.child[0])
+	checkCompact(t, node.child[1])
+}
+
+func TestPrefixTrie(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tr := newPrefixTrie[int](0)
+	want := make(map[Prefix]int)
+	for i := 0; i < 5000; i++ {
+		k := randomPrefix(r)
+		if r.Intn(3) == 0 {
+			tr.del(k)
+			delete(want, k)
+		} else {
+			tr.set(k, i)
+			want[k] = i
+		}
+		if tr.len() != len(want) {
+			t.Fatalf("len = %d after %d operations, want %d", tr.len(), i+1, len(want))
+		}
+		a := randomPrefix(r)
+		var best Prefix
+		found, longer := false, false
+		for k := range want {
+			if k.contains(a) && (!found || k.Bits > best.Bits) {
+				best, found = k, true
+			}
+			longer = longer || k.Bits > a.Bits && a.contains(k)
+		}
+		k, v, ok := tr.longest(a)
+		if ok != found || ok && (k != best || v != want[best]) {
+			t.Fatalf("longest(%s) = %s, %d, %v, want %s, %d, %v", a, k, v, ok, best, want[best], found)
+		}
+		if got := tr.hasLonger(a); got != longer {
+			t.Fatalf("hasLonger(%s) = %v, want %v", a, got, longer)
+		}
+		wv, wok := want[a]
+		if v, ok := tr.get(a); ok != wok || v != wv {
+			t.Fatalf("get(%s) = %d, %v, want %d, %v", a, v, ok, wv, wok)
+		}
+	}
+	checkCompact(t, tr.short)
+	for _, root := range tr.ouis {
+		checkCompact(t, root)
+	}
+	n := 0
+	tr.walk(func(k Prefix, v int) bool {
+		if want[k] != v {
+			t.Errorf("walk gave %s = %d, want %d", k, v, want[k])
+		}
+		n++
+		return true
+	})
+	if n != len(want) {
+		t.Errorf("walk gave %d prefixes, want %d", n, len(want))
+	}
+	c := tr.clone()
+	for k := range want {
+		tr.del(k)
+	}
+	if tr.len() != 0 || len(tr.ouis) != 0 || tr.short != nil {
+		t.Errorf("trie not empty after deleting all prefixes")
+	}
+	if c.len() != len(want) {
+		t.Errorf("clone has %d prefixes, want %d", c.len(), len(want))
+	}
+}
+
+// Prefixes like a database with all registries loaded: 24 bit MA-L prefixes,
+// OUIs with 16 MA-M blocks and OUIs with 4096 MA-S blocks,
+// and addresses within them to look up.
+func mixedPrefixes() ([]Prefix, []Prefix) {
+	r := rand.New(rand.NewSource(1))
+	var prefixes, addrs []Prefix
+	oui := func() Prefix {
+		return Prefix{Addr: [6]byte{byte(r.Intn(256)) &^ 3, byte(r.Intn(256)), byte(r.Intn(256))}, Bits: 24}
+	}
+	for i := 0; i < 30000; i++ {
+		prefixes = append(prefixes, oui())
+	}
+	for i := 0; i < 400; i++ {
+		p := oui()
+		prefixes = append(prefixes, p)
+		for j := 0; j < 16; j++ {
+			p.Addr[3], p.Bits = byte(j<<4), 28
+			prefixes = append(prefixes, p)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		p := oui()
+		prefixes = append(prefixes, p)
+		for j := 0; j < 4096; j++ {
+			p.Addr[3], p.Addr[4], p.Bits = byte(j>>4), byte(j<<4), 36
+			prefixes = append(prefixes, p)
+		}
+	}
+	for i := 0; i < 4096; i++ {
+		a := prefixes[r.Intn(len(prefixes))]
+		a.Addr[3], a.Addr[4], a.Addr[5], a.Bits = byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), 48
+		addrs = append(addrs, a)
+	}
+	return prefixes, addrs
+}
+
+func BenchmarkLongestPrefix(b *testing.B) {
+	prefixes, addrs := mixedPrefixes()
+	b.Run("trie", func(b *testing.B) {
+		tr := newPrefixTrie[int](0)
+		for i, p := range prefixes {
+			tr.set(p, i)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			tr.longest(addrs[i%len(addrs)])
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		m := &lengthMap{m: make(map[Prefix]int)}
+		for i, p := range prefixes {
+			m.set(p, i)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.longest(addrs[i%len(addrs)])
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package oui
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidEntry is returned by Validate for an entry
+// that is inconsistent.
+type ErrInvalidEntry struct {
+	Prefix HardwareAddr
+	Reason string
+}
+
+// Error returns a string representation of the error.
+func (e ErrInvalidEntry) Error() string {
+	return fmt.Sprintf("invalid entry %s: %s", e.Prefix, e.Reason)
+}
+
+// Validate will check the entries of the database for problems, like empty
+// manufacturers, malformed prefixes, and assignments overlapping
+// entries assigned to another manufacturer.
+// All problems found are returned as ErrInvalidEntry errors, sorted by prefix.
+// The database is not modified.
+func Validate(db OuiDB) []error {
+	var entries []*Entry
+	db.walk(func(e Entry) bool {
+		entries = append(entries, &e)
+		return true
+	})
+	sortEntries(entries)
+	var errs []error
+	invalid := func(e *Entry, format string, args ...interface{}) {
+		errs = append(errs, ErrInvalidEntry{Prefix: e.Prefix, Reason: fmt.Sprintf(format, args...)})
+	}
+	// Entries assigned with less than 24 bits, which cover other prefixes.
+	var blocks []*Entry
+	for _, e := range entries {
+		if strings.TrimSpace(e.Manufacturer) == "" {
+			invalid(e, "empty manufacturer")
+		}
+		if e.PrefixLen < 0 || e.PrefixLen > 48 {
+			invalid(e, "prefix length %d out of range", e.PrefixLen)
+			continue
+		}
+		if e.Local != e.Prefix.Local() || e.Multicast != e.Prefix.Multicast() {
+			invalid(e, "local/multicast flags do not match prefix")
+		}
+		bits := e.bits()
+		raw := Prefix{Addr: [6]byte{e.Prefix[0], e.Prefix[1], e.Prefix[2], e.Extension[0], e.Extension[1], e.Extension[2]}, Bits: bits}
+		if raw.masked() != raw {
+			invalid(e, "bits set after prefix length %d", bits)
+			continue
+		}
+		if bits < 24 {
+			blocks = append(blocks, e)
+		}
+	}
+	for _, b := range blocks {
+		for _, e := range entries {
+			if e == b || !b.Assignment().contains(e.Assignment()) {
+				continue
+			}
+			if e.Manufacturer != b.Manufacturer {
+				invalid(e, "overlaps %s/%d assigned to %q", b.Prefix, b.bits(), b.Manufacturer)
+			}
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		a, b := errs[i].(ErrInvalidEntry).Prefix, errs[j].(ErrInvalidEntry).Prefix
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	return errs
+}
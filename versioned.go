@@ -0,0 +1,129 @@
+package oui
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// VersionedDB is a collection of dated snapshots of a database,
+// that can be used to look up what an address was assigned to
+// at a given time.
+// It is safe for concurrent use.
+type VersionedDB struct {
+	mu        sync.RWMutex
+	snapshots []snapshot
+	// Entries of all snapshots by hash, so identical entries can share content.
+	shared map[uint64]*sharedEntry
+}
+
+// An entry shared by snapshots, and the number of snapshots using it.
+type sharedEntry struct {
+	e    Entry
+	refs int
+}
+
+// A database effective from a date.
+type snapshot struct {
+	from time.Time
+	db   DynamicDB
+	// The hashes of the shared entries used by the snapshot.
+	hashes []uint64
+}
+
+// Databases that can replace their entries with identical entries in place.
+type entrySharer interface {
+	share(fn func(e Entry) (Entry, bool))
+}
+
+// NewVersionedDB will return an empty versioned database.
+// Add snapshots with Add.
+func NewVersionedDB() *VersionedDB {
+	return &VersionedDB{shared: make(map[uint64]*sharedEntry)}
+}
+
+// Add will add a snapshot that is effective from the given date,
+// until the date of the next snapshot.
+// Snapshots can be added in any order. A snapshot with the same date
+// as an existing snapshot replaces it.
+//
+// To keep memory usage down, entries held in memory that are identical
+// to entries in previously added snapshots are replaced in db,
+// so they share their content. Entries shadowed by them are kept.
+// Entries are no longer shared once the last snapshot using them is replaced.
+// The database should not be updated after it has been added.
+func (v *VersionedDB) Add(date time.Time, db DynamicDB) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var hashes []uint64
+	use := func(e Entry) (Entry, bool) {
+		h := e.Hash()
+		s, ok := v.shared[h]
+		if !ok {
+			s = &sharedEntry{e: e}
+			v.shared[h] = s
+		} else if !s.e.Equal(&e) {
+			return e, false
+		}
+		s.refs++
+		hashes = append(hashes, h)
+		return s.e, ok
+	}
+	if sh, ok := db.(entrySharer); ok {
+		sh.share(use)
+	} else {
+		db.walk(func(e Entry) bool {
+			use(e)
+			return true
+		})
+	}
+	i := sort.Search(len(v.snapshots), func(i int) bool {
+		return !v.snapshots[i].from.Before(date)
+	})
+	if i < len(v.snapshots) && v.snapshots[i].from.Equal(date) {
+		v.release(v.snapshots[i].hashes)
+		v.snapshots[i] = snapshot{from: date, db: db, hashes: hashes}
+		return
+	}
+	v.snapshots = append(v.snapshots, snapshot{})
+	copy(v.snapshots[i+1:], v.snapshots[i:])
+	v.snapshots[i] = snapshot{from: date, db: db, hashes: hashes}
+}
+
+// Release the shared entries of a snapshot that is no longer used.
+func (v *VersionedDB) release(hashes []uint64) {
+	for _, h := range hashes {
+		if s := v.shared[h]; s != nil {
+			s.refs--
+			if s.refs <= 0 {
+				delete(v.shared, h)
+			}
+		}
+	}
+}
+
+// AsOf returns the snapshot effective at the given time.
+// If no snapshot is effective at that time, nil is returned.
+func (v *VersionedDB) AsOf(when time.Time) DynamicDB {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	i := sort.Search(len(v.snapshots), func(i int) bool {
+		return v.snapshots[i].from.After(when)
+	})
+	if i == 0 {
+		return nil
+	}
+	return v.snapshots[i-1].db
+}
+
+// LookUpAsOf will look up a hardware address in the snapshot
+// effective at the given time.
+// If no snapshot is effective at that time, or the address isn't
+// in the snapshot, a NotFoundError will be returned.
+func (v *VersionedDB) LookUpAsOf(hw HardwareAddr, when time.Time) (*Entry, error) {
+	db := v.AsOf(when)
+	if db == nil {
+		return nil, NotFoundError{Addr: hw}
+	}
+	return db.LookUp(hw)
+}
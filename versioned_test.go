@@ -0,0 +1,71 @@
+package oui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVersionedDB(t *testing.T) {
+	hw := HardwareAddr{0x00, 0x22, 0x72}
+	record := "00-22-72   (hex)\t\tOld Name\r\n002272     (base 16)\t\tOld Name\r\n\r\n"
+	b, err := OpenFile("testdata/oui.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all strings.Builder
+	if err := ExportFiltered(b, &all, nil, FormatOUI); err != nil {
+		t.Fatal(err)
+	}
+	// The entry of hw shadows an older entry in both snapshots.
+	open := func() DynamicDB {
+		db, err := Open(strings.NewReader(record+all.String()), WithKeepDuplicates())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	first, second := open(), open()
+
+	v := NewVersionedDB()
+	jan := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun := time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)
+	v.Add(jan, first)
+	v.Add(jun, second)
+	n := len(v.shared)
+	if n != first.Len() {
+		t.Errorf("%d shared entries, want %d", n, first.Len())
+	}
+	// Sharing entries keeps the duplicates of the snapshots.
+	c, err := second.LookUpCandidates(hw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 2 || c[1].Manufacturer != "Old Name" {
+		t.Errorf("LookUpCandidates after Add returned %d entries", len(c))
+	}
+	e, err := v.LookUpAsOf(hw, jun.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Manufacturer != "American Micro-Fuel Device Corp." {
+		t.Errorf("LookUpAsOf = %q", e.Manufacturer)
+	}
+	if _, err := v.LookUpAsOf(hw, jan.Add(-time.Hour)); err == nil {
+		t.Error("found an entry before the first snapshot")
+	}
+
+	// Replacing the snapshots releases the entries only they used.
+	empty, err := Open(strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Add(jan, empty)
+	if len(v.shared) != n {
+		t.Errorf("%d shared entries with one snapshot left, want %d", len(v.shared), n)
+	}
+	v.Add(jun, empty)
+	if len(v.shared) != 0 {
+		t.Errorf("%d shared entries after replacing all snapshots, want 0", len(v.shared))
+	}
+}
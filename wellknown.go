@@ -0,0 +1,28 @@
+package oui
+
+// 24 bit prefixes reserved for protocols rather than assigned to a vendor.
+var wellKnown = map[HardwareAddr]string{
+	{0x00, 0x00, 0x5e}: "IANA (VRRP and other protocols)",
+	{0x01, 0x00, 0x0c}: "Cisco protocols (CDP, VTP, PVST+)",
+	{0x01, 0x00, 0x5e}: "IPv4 multicast",
+	{0x01, 0x0c, 0xcd}: "IEC 61850 (GOOSE, sampled values)",
+	{0x01, 0x1b, 0x19}: "IEEE 1588 Precision Time Protocol",
+	{0x01, 0x80, 0xc2}: "IEEE 802.1 bridge group (spanning tree, LLDP, LACP)",
+	{0xff, 0xff, 0xff}: "Broadcast",
+}
+
+// 16 bit prefixes reserved for protocols.
+var wellKnown16 = map[[2]byte]string{
+	{0x33, 0x33}: "IPv6 multicast",
+}
+
+// WellKnown will return a label for hardware addresses that are reserved
+// for protocols, like spanning tree or IPv4 and IPv6 multicast.
+// If the address isn't in the reserved list, false is returned.
+func WellKnown(hw HardwareAddr) (string, bool) {
+	if s, ok := wellKnown[hw]; ok {
+		return s, true
+	}
+	s, ok := wellKnown16[[2]byte{hw[0], hw[1]}]
+	return s, ok
+}